@@ -24,7 +24,9 @@ import (
 )
 
 func main() {
-	if err := cmd.Command(gem.Default, gemcmd.OsStreams).Execute(); err != nil {
-		os.Exit(1)
+	err := cmd.Command(gem.Default, gem.DefaultGitCache, gem.DefaultAuthProvider, gemcmd.OsStreams).Execute()
+	_ = gem.DefaultGitCache.Close()
+	if err != nil {
+		os.Exit(gemcmd.ExitCode(err))
 	}
 }
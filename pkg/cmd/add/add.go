@@ -0,0 +1,156 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package add
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	gemcmd "github.com/gardener/gem/pkg/cmd"
+	"github.com/gardener/gem/pkg/cmd/ensure"
+	"github.com/gardener/gem/pkg/gem"
+	gemapi "github.com/gardener/gem/pkg/gem/api"
+	gemv1alpha2 "github.com/gardener/gem/pkg/gem/api/v1alpha2"
+	"github.com/spf13/cobra"
+)
+
+const versionSeparator = "@"
+
+func Command(g gem.Interface, streams *gemcmd.Streams) *cobra.Command {
+	var (
+		requirementsFilename            string
+		locksFilename                   string
+		controllerRegistrationsFilename string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "add NAME[@VERSION]...",
+		Short: "Adds requirements to the requirements file and ensures the locks and controller registrations",
+		Long: `Adds requirements to the requirements file and ensures the locks and controller registrations.
+The VERSION is a version constraint, e.g. ^1.2, without it the latest commit of the default branch is required.
+Every module is solved before it is added to make sure it exists and has a controller registration file.
+The requirements are added to the file itself, not to included files or profiles.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := gemcmd.Context(cmd)
+			defer cancel()
+
+			if profile := gemcmd.Profile(cmd); profile != "" {
+				return fmt.Errorf("--%s is not supported by %s, edit the requirements of profile %s in %s instead", gemcmd.DefaultProfileFlag, cmd.Name(), profile, requirementsFilename)
+			}
+
+			return Run(ctx, g, streams, requirementsFilename, locksFilename, controllerRegistrationsFilename, args)
+		},
+	}
+
+	cmd.Flags().StringVar(&requirementsFilename, gemcmd.DefaultRequirementsFilenameFlag, gemcmd.DefaultRequirementsFilename, gemcmd.DefaultRequirementsFilenameUsage)
+	cmd.Flags().StringVar(&locksFilename, gemcmd.DefaultLocksFilenameFlag, gemcmd.DefaultLocksFilename, gemcmd.DefaultLocksFilenameUsage)
+	cmd.Flags().StringVar(&controllerRegistrationsFilename, gemcmd.DefaultControllerRegistrationsFilenameFlag, gemcmd.DefaultControllerRegistrationsFilename, gemcmd.DefaultControllerRegistrationsFilenameUsage)
+
+	return cmd
+}
+
+// parseArg parses an argument of the form NAME[@VERSION]. Only an `@` after the last slash separates the
+// version, so user infos of URLs like `ssh://git@github.com/org/repo` are kept.
+func parseArg(arg string) (gemapi.ModuleKey, *gemapi.Requirement, error) {
+	name, target := arg, gemapi.Target{Type: gemapi.Latest}
+	if idx := strings.LastIndex(arg, versionSeparator); idx > strings.LastIndex(arg, "/") {
+		name, target = arg[:idx], gemapi.Target{Type: gemapi.Version, Version: arg[idx+len(versionSeparator):]}
+		if target.Version == "" {
+			return gemapi.ModuleKey{}, nil, fmt.Errorf("empty version in %s", arg)
+		}
+	}
+
+	moduleKey, err := gemcmd.ParseModuleName(name)
+	if err != nil {
+		return gemapi.ModuleKey{}, nil, err
+	}
+	return moduleKey, &gemapi.Requirement{Target: target, Filename: gemv1alpha2.DefaultRequirementFilename}, nil
+}
+
+// repositoryURLs returns the URLs the repositories of the given requirements are cloned from, if they differ from
+// the repositories.
+func repositoryURLs(requirements *gemapi.Requirements) map[string]string {
+	urls := make(map[string]string)
+	for moduleKey, requirement := range requirements.Requirements {
+		if requirement.URL != "" {
+			urls[moduleKey.Repository] = requirement.URL
+		}
+	}
+	return urls
+}
+
+func Run(ctx context.Context, g gem.Interface, streams *gemcmd.Streams, requirementsFilename, locksFilename, controllerRegistrationsFilename string, args []string) error {
+	existing, err := gem.LoadRequirementsFromFile(requirementsFilename)
+	if err != nil {
+		return err
+	}
+	// Included requirements determine the URLs of their repositories as well, like when ensuring the edited file.
+	resolved, err := g.ResolveIncludesContext(ctx, existing, requirementsFilename)
+	if err != nil {
+		return err
+	}
+	urls := repositoryURLs(resolved)
+
+	var (
+		moduleKeys   []gemapi.ModuleKey
+		requirements []*gemapi.Requirement
+	)
+	for _, arg := range args {
+		moduleKey, requirement, err := parseArg(arg)
+		if err != nil {
+			return err
+		}
+
+		// Modules of a repository that is already required are cloned from the same URL.
+		requirement.URL = urls[moduleKey.Repository]
+		repositoryName := moduleKey.Repository
+		if requirement.URL != "" {
+			repositoryName = requirement.URL
+		}
+
+		repositoryInterface, err := g.RepositoryContext(ctx, repositoryName)
+		if err != nil {
+			return fmt.Errorf("could not retrieve repository of %s: %w", &moduleKey, err)
+		}
+		// The requirement is solved with the prerelease policy of the file and the deny list, like ensure does.
+		solved := *requirement
+		if solved.Target.Type == gemapi.Version {
+			solved.Target.Prereleases = existing.Prereleases
+			if denyList := g.DenyList(); denyList != nil {
+				solved.Target.Exclude = denyList.Denied[moduleKey]
+			}
+		}
+		if _, err := repositoryInterface.SolveContext(ctx, moduleKey.Submodule, &solved); err != nil {
+			return fmt.Errorf("could not solve requirement for %s: %w", &moduleKey, err)
+		}
+
+		moduleKeys = append(moduleKeys, moduleKey)
+		requirements = append(requirements, requirement)
+	}
+
+	return gemcmd.EditRequirementsFile(requirementsFilename, []string{locksFilename, controllerRegistrationsFilename}, func(data []byte) ([]byte, error) {
+		for i, moduleKey := range moduleKeys {
+			var err error
+			if data, err = gem.AddRequirement(data, moduleKey, requirements[i]); err != nil {
+				return nil, err
+			}
+		}
+		return data, nil
+	}, func() error {
+		return ensure.Run(ctx, g, streams, requirementsFilename, "", locksFilename, controllerRegistrationsFilename, false, nil, "")
+	})
+}
@@ -0,0 +1,36 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	gemcmd "github.com/gardener/gem/pkg/cmd"
+	"github.com/gardener/gem/pkg/gem"
+	"github.com/spf13/cobra"
+)
+
+func Command(cache gem.GitCache, streams *gemcmd.Streams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manages the cache of git repositories",
+	}
+
+	cmd.AddCommand(
+		ListCommand(cache, streams),
+		PruneCommand(cache, streams),
+		ClearCommand(cache, streams),
+	)
+
+	return cmd
+}
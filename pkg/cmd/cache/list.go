@@ -0,0 +1,53 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	gemcmd "github.com/gardener/gem/pkg/cmd"
+	"github.com/gardener/gem/pkg/gem"
+	"github.com/spf13/cobra"
+)
+
+func ListCommand(cache gem.GitCache, streams *gemcmd.Streams) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "Lists the cached git repositories",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunList(cache, streams)
+		},
+	}
+}
+
+func RunList(cache gem.GitCache, streams *gemcmd.Streams) error {
+	entries, err := cache.List()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(streams.Out, 0, 8, 2, ' ', 0)
+	if _, err := fmt.Fprintln(w, "NAME\tSIZE\tLAST USED"); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\n", entry.Name, gemcmd.FormatBytes(entry.Size), entry.LastUsed.Format(time.RFC3339)); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
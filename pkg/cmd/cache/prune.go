@@ -0,0 +1,50 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"fmt"
+	"time"
+
+	gemcmd "github.com/gardener/gem/pkg/cmd"
+	"github.com/gardener/gem/pkg/gem"
+	"github.com/spf13/cobra"
+)
+
+func PruneCommand(cache gem.GitCache, streams *gemcmd.Streams) *cobra.Command {
+	var maxAge time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Removes cached git repositories that have not been used for a while",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunPrune(cache, streams, maxAge)
+		},
+	}
+
+	cmd.Flags().DurationVar(&maxAge, gemcmd.DefaultCacheMaxAgeFlag, gemcmd.DefaultCacheMaxAge, gemcmd.DefaultCacheMaxAgeUsage)
+
+	return cmd
+}
+
+func RunPrune(cache gem.GitCache, streams *gemcmd.Streams, maxAge time.Duration) error {
+	pruned, err := cache.Prune(maxAge)
+	for _, entry := range pruned {
+		if _, err := fmt.Fprintf(streams.Out, "Pruned %s\n", entry.Name); err != nil {
+			return err
+		}
+	}
+	return err
+}
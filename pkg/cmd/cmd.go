@@ -15,27 +15,98 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"k8s.io/apimachinery/pkg/runtime"
 	"os"
+	"path/filepath"
+	"strings"
 
 	gemv1alpha1 "github.com/gardener/gem/pkg/gem/api/v1alpha1"
+	gemv1alpha2 "github.com/gardener/gem/pkg/gem/api/v1alpha2"
 
 	gemioutil "github.com/gardener/gem/pkg/util/io"
 
 	"github.com/gardener/gem/pkg/gem"
 
 	gemapi "github.com/gardener/gem/pkg/gem/api"
+	gemapilatest "github.com/gardener/gem/pkg/gem/api/latest"
 
 	osutil "github.com/gardener/gem/pkg/util/os"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/openpgp"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 const (
 	streamIdent = "-"
 )
 
+// ExitError is an error that makes gem exit with the given code instead of the one of generic failures.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the code gem exits with for the given error.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	return ExitCodeFailure
+}
+
+// Context returns the context of the given command, limited by the timeout flag if it is set.
+func Context(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	timeout, err := cmd.Flags().GetDuration(DefaultTimeoutFlag)
+	if err != nil || timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// Profile returns the value of the profile flag of the given command.
+func Profile(cmd *cobra.Command) string {
+	profile, err := cmd.Flags().GetString(DefaultProfileFlag)
+	if err != nil {
+		return ""
+	}
+	return profile
+}
+
+// ProfileFilename returns the filename for the given flag of the command. If a profile is selected and the flag
+// was not set explicitly, the profile name is inserted before the extension, e.g. `locks.dev.yaml`.
+func ProfileFilename(cmd *cobra.Command, flag, filename string) string {
+	profile := Profile(cmd)
+	if profile == "" || filename == streamIdent || cmd.Flags().Changed(flag) {
+		return filename
+	}
+
+	ext := filepath.Ext(filename)
+	return fmt.Sprintf("%s.%s%s", strings.TrimSuffix(filename, ext), profile, ext)
+}
+
 func FileOrReadCloser(filename string, rc io.ReadCloser) (io.ReadCloser, error) {
 	if filename == streamIdent {
 		return rc, nil
@@ -85,6 +156,102 @@ func LoadRequirementsFromFileOrReadCloser(filename string, rc io.ReadCloser) (*g
 	return gem.LoadRequirements(data)
 }
 
+// LoadProfileRequirementsFromFileOrReadCloser loads the requirements, resolves their includes and applies the
+// given profile, if any. Includes of requirements read from the ReadCloser are resolved relative to the working directory.
+func LoadProfileRequirementsFromFileOrReadCloser(ctx context.Context, g gem.Interface, filename, profile string, rc io.ReadCloser) (*gemapi.Requirements, error) {
+	requirements, err := LoadRequirementsFromFileOrReadCloser(filename, rc)
+	if err != nil {
+		return nil, err
+	}
+
+	includeFilename := filename
+	if filename == streamIdent {
+		includeFilename = ""
+	}
+	if requirements, err = g.ResolveIncludesContext(ctx, requirements, includeFilename); err != nil {
+		return nil, err
+	}
+
+	return gem.ApplyProfile(requirements, profile)
+}
+
+// fileSnapshot is the content of a file before it was modified, to restore it on failure.
+type fileSnapshot struct {
+	filename string
+	data     []byte
+	// exists is false if the file did not exist, it is then removed on restore.
+	exists bool
+}
+
+func snapshotFile(filename string) (*fileSnapshot, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &fileSnapshot{filename: filename}, nil
+		}
+		return nil, err
+	}
+	return &fileSnapshot{filename: filename, data: data, exists: true}, nil
+}
+
+func (s *fileSnapshot) restore() error {
+	if !s.exists {
+		if err := os.Remove(s.filename); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return ioutil.WriteFile(s.filename, s.data, 0600)
+}
+
+// EditRequirementsFile rewrites the requirements file via edit and then calls apply. If apply fails, the
+// original requirements file and the given output files apply may have written, e.g. the locks, are restored.
+// Streamed output files are not restored.
+func EditRequirementsFile(filename string, outputFilenames []string, edit func(data []byte) ([]byte, error), apply func() error) error {
+	requirements, err := snapshotFile(filename)
+	if err != nil {
+		return err
+	}
+	if !requirements.exists {
+		return &os.PathError{Op: "open", Path: filename, Err: os.ErrNotExist}
+	}
+
+	snapshots := []*fileSnapshot{requirements}
+	for _, outputFilename := range outputFilenames {
+		if outputFilename == streamIdent {
+			continue
+		}
+		snapshot, err := snapshotFile(outputFilename)
+		if err != nil {
+			return err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	edited, err := edit(requirements.data)
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(filename, edited, 0600); err != nil {
+		return err
+	}
+
+	if err := apply(); err != nil {
+		var failed []string
+		for _, snapshot := range snapshots {
+			if restoreErr := snapshot.restore(); restoreErr != nil {
+				failed = append(failed, fmt.Sprintf("%s: %v", snapshot.filename, restoreErr))
+			}
+		}
+		if len(failed) > 0 {
+			return fmt.Errorf("%w (could not restore %s)", err, strings.Join(failed, ", "))
+		}
+		return err
+	}
+	return nil
+}
+
 func WriteRequirementsIntoFileOrWriteCloser(requirements *gemapi.Requirements, filename string, wc io.WriteCloser) error {
 	wc, err := FileOrWriteCloser(filename, wc)
 	if err != nil {
@@ -104,14 +271,36 @@ func LoadLocksFromFileOrReadCloser(filename string, rc io.ReadCloser) (*gemapi.L
 	return gem.LoadLocks(data)
 }
 
-func WriteLocksIntoFileOrWriteCloser(locks *gemapi.Locks, filename string, wc io.WriteCloser) error {
-	wc, err := FileOrWriteCloser(filename, wc)
+// LocksVersion returns the API version of the given locks file, so that it can be written in the version it was
+// read in. Locks that do not exist yet or are streamed are written in the latest version.
+func LocksVersion(filename string) (schema.GroupVersion, error) {
+	if filename == streamIdent {
+		return gemapilatest.GroupVersion, nil
+	}
+
+	data, err := ioutil.ReadFile(filename)
 	if err != nil {
+		if os.IsNotExist(err) {
+			return gemapilatest.GroupVersion, nil
+		}
+		return schema.GroupVersion{}, err
+	}
+
+	return gem.LocksVersion(data)
+}
+
+// WriteLocksIntoFileOrWriteCloser writes the locks in the given API version. The file is left untouched if the
+// locks cannot be represented in that version.
+func WriteLocksIntoFileOrWriteCloser(locks *gemapi.Locks, gv schema.GroupVersion, filename string, wc io.WriteCloser) error {
+	data, err := gem.WriteLocksVersion(locks, gv)
+	if err != nil {
+		if gv != gemapilatest.GroupVersion {
+			return fmt.Errorf("could not write locks in %s, run gem migrate to upgrade them: %w", gv, err)
+		}
 		return err
 	}
-	defer gemioutil.CloseSilently(wc)
 
-	return gem.WriteLocksInto(locks, wc)
+	return WriteAllFileOrWriteCloser(filename, wc, data)
 }
 
 func WriteControllerRegistrationsInto(registrations []runtime.Object, w io.Writer) error {
@@ -142,18 +331,116 @@ func WriteControllerRegistrationsIntoFileOrWriteCloser(registrations []runtime.O
 	return WriteControllerRegistrationsInto(registrations, wc)
 }
 
-func UpdateFlagsToUpdatePolicy(updateAll bool, updateNames []string) (gem.UpdatePolicy, error) {
+// LoadCredentialsIntoAuthProvider loads the credentials file into the given auth provider.
+// A missing file is only an error if required is set.
+func LoadCredentialsIntoAuthProvider(auth gem.CredentialsAuthProvider, filename string, required bool) error {
+	if filename == "" {
+		return nil
+	}
+
+	credentials, err := gem.LoadCredentialsFromFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) && !required {
+			return nil
+		}
+		return fmt.Errorf("could not load credentials from %s: %w", filename, err)
+	}
+
+	auth.SetCredentials(credentials)
+	return nil
+}
+
+// LoadDenyList loads the deny list from the given file. It returns nil if no file is given.
+func LoadDenyList(filename string) (*gemapi.DenyList, error) {
+	if filename == "" {
+		return nil, nil
+	}
+
+	denyList, err := gem.LoadDenyListFromFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not load deny list from %s: %w", filename, err)
+	}
+	return denyList, nil
+}
+
+// LoadSignatureVerifier loads the keyring and the allowed signers file into a SignatureVerifier.
+// It returns nil if neither file is given, i.e. if signatures should not be verified.
+func LoadSignatureVerifier(keyringFilename, allowedSignersFilename string) (gem.SignatureVerifier, error) {
+	if keyringFilename == "" && allowedSignersFilename == "" {
+		return nil, nil
+	}
+
+	var keyring openpgp.EntityList
+	if keyringFilename != "" {
+		var err error
+		if keyring, err = gem.LoadKeyringFromFile(keyringFilename); err != nil {
+			return nil, fmt.Errorf("could not load keyring from %s: %w", keyringFilename, err)
+		}
+	}
+
+	var allowedSigners []gem.AllowedSigner
+	if allowedSignersFilename != "" {
+		var err error
+		if allowedSigners, err = gem.LoadAllowedSignersFromFile(allowedSignersFilename); err != nil {
+			return nil, fmt.Errorf("could not load allowed signers from %s: %w", allowedSignersFilename, err)
+		}
+	}
+
+	return gem.NewKeyringSignatureVerifier(keyring, allowedSigners), nil
+}
+
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// ParseModuleName parses the name of a module given on the command line. Names separating repository and submodule
+// via `//` are parsed like v1alpha2 sources, all other names are split like the names of v1alpha1.
+func ParseModuleName(name string) (gemapi.ModuleKey, error) {
+	if gemv1alpha2.IsSource(name) {
+		return gemv1alpha2.ParseSource(name)
+	}
+	return gemv1alpha1.ExtractModuleKeyFromName(name)
+}
+
+func UpdateFlagsToUpdatePolicy(updateAll bool, updateNames []string, updateStrategy string) (gem.UpdatePolicy, error) {
 	if updateAll && len(updateNames) > 0 {
 		return nil, fmt.Errorf("cannot update all and specific names at the same time")
 	}
 
+	if updateStrategy != "" {
+		if !updateAll && len(updateNames) == 0 {
+			return nil, fmt.Errorf("an update strategy requires requirements to update")
+		}
+
+		strategy, err := gemapi.ParseUpdateStrategy(updateStrategy)
+		if err != nil {
+			return nil, err
+		}
+
+		updatePolicy, err := UpdateFlagsToUpdatePolicy(updateAll, updateNames, "")
+		if err != nil {
+			return nil, err
+		}
+		return gem.WithUpdateStrategy(updatePolicy, strategy), nil
+	}
+
 	if updateAll {
 		return gem.UpdateAll, nil
 	}
 
 	set := gem.NewModuleKeySet()
 	for _, updateName := range updateNames {
-		moduleKey, err := gemv1alpha1.ExtractModuleKeyFromName(updateName)
+		moduleKey, err := ParseModuleName(updateName)
 		if err != nil {
 			return nil, err
 		}
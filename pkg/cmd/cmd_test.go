@@ -0,0 +1,91 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEditRequirementsFile(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		applyErr error
+		// want are the contents of the files afterwards, an empty content means the file does not exist.
+		want map[string]string
+	}{
+		{
+			name: "apply succeeds",
+			want: map[string]string{
+				"requirements.yaml":             "edited",
+				"locks.yaml":                    "new locks",
+				"controller-registrations.yaml": "new registrations",
+			},
+		},
+		{
+			name:     "apply fails after writing the locks",
+			applyErr: errors.New("fetch failed"),
+			want: map[string]string{
+				"requirements.yaml":             "original",
+				"locks.yaml":                    "old locks",
+				"controller-registrations.yaml": "",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := func(name string) string { return filepath.Join(dir, name) }
+			for name, data := range map[string]string{"requirements.yaml": "original", "locks.yaml": "old locks"} {
+				if err := ioutil.WriteFile(path(name), []byte(data), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := EditRequirementsFile(path("requirements.yaml"), []string{path("locks.yaml"), path("controller-registrations.yaml"), streamIdent}, func(data []byte) ([]byte, error) {
+				return []byte("edited"), nil
+			}, func() error {
+				if err := ioutil.WriteFile(path("locks.yaml"), []byte("new locks"), 0600); err != nil {
+					return err
+				}
+				if err := ioutil.WriteFile(path("controller-registrations.yaml"), []byte("new registrations"), 0600); err != nil {
+					return err
+				}
+				return tc.applyErr
+			})
+			if err != tc.applyErr {
+				t.Fatalf("EditRequirementsFile returned %v, want %v", err, tc.applyErr)
+			}
+
+			for name, want := range tc.want {
+				data, err := ioutil.ReadFile(path(name))
+				if want == "" {
+					if !os.IsNotExist(err) {
+						t.Fatalf("%s exists with %q, want it removed", name, data)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(data) != want {
+					t.Fatalf("%s contains %q, want %q", name, data, want)
+				}
+			}
+		})
+	}
+}
@@ -17,6 +17,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
@@ -35,14 +36,63 @@ const (
 	DefaultControllerRegistrationsFilenameUsage = "Path to the controller-registrations file"
 
 	DefaultUpdateFlag      = "update"
-	DefaultUpdateFlagUsage = "Names of requirements to update"
+	DefaultUpdateFlagUsage = "Names of requirements to update, separate repository and submodule via // if the repository has more than three path segments"
+
+	DefaultUpdateStrategyFlag  = "update-strategy"
+	DefaultUpdateStrategyUsage = "Limits how far the updated requirements may move relative to their locked versions, one of patch, minor, major or latest-in-range, overrides the update strategies of the requirements"
 
 	DefaultUpdateAll      = false
 	DefaultUpdateAllFlag  = "update-all"
 	DefaultUpdateAllUsage = "Whether to update all requirements or not"
 
+	DefaultFrozen      = false
+	DefaultFrozenFlag  = "frozen"
+	DefaultFrozenUsage = "Fail instead of writing anything if the locks or controller registrations are not up to date"
+
+	DefaultProfileFlag  = "profile"
+	DefaultProfileUsage = "Name of the requirements profile to use, the locks and controller-registrations files default to per-profile files"
+
 	DefaultLogLevelFlag  = "log-level"
 	DefaultLogLevelFlagP = "v"
+
+	DefaultCacheDirFlag  = "cache-dir"
+	DefaultCacheDirUsage = "Directory to cache git repositories in, an empty value disables the cache"
+
+	DefaultCredentialsFilenameFlag  = "credentials"
+	DefaultCredentialsFilenameUsage = "Path to the credentials file used to access git repositories"
+
+	DefaultKeyringFlag  = "keyring"
+	DefaultKeyringUsage = "Path to a PGP keyring whose keys are trusted to sign the resolved tags and commits"
+
+	DefaultAllowedSignersFlag  = "allowed-signers"
+	DefaultAllowedSignersUsage = "Path to an allowed signers file whose SSH keys are trusted to sign the resolved tags and commits"
+
+	DefaultDenyListFlag  = "deny-list"
+	DefaultDenyListUsage = "Path to a deny list of extension versions that must never be resolved"
+
+	DefaultJobsFlag  = "jobs"
+	DefaultJobsFlagP = "j"
+	DefaultJobsUsage = "Maximum number of modules to process concurrently"
+
+	DefaultFailFast      = false
+	DefaultFailFastFlag  = "fail-fast"
+	DefaultFailFastUsage = "Whether to stop at the first failing extension instead of reporting all failures"
+
+	DefaultOutputFlag  = "output"
+	DefaultOutputFlagP = "o"
+
+	DefaultTimeout      = time.Duration(0)
+	DefaultTimeoutFlag  = "timeout"
+	DefaultTimeoutUsage = "Maximum duration of the command, zero means no timeout"
+
+	DefaultCacheMaxAge      = 30 * 24 * time.Hour
+	DefaultCacheMaxAgeFlag  = "max-age"
+	DefaultCacheMaxAgeUsage = "Maximum duration since the last use of a cached repository"
+
+	// ExitCodeFailure is the exit code of failed commands.
+	ExitCodeFailure = 1
+	// ExitCodeOutdated is the exit code of outdated if any extension is outdated.
+	ExitCodeOutdated = 2
 )
 
 var (
@@ -15,10 +15,13 @@
 package ensure
 
 import (
+	"context"
+	"fmt"
 	"io/ioutil"
 	"os"
 
 	gemcmd "github.com/gardener/gem/pkg/cmd"
+	"github.com/gardener/gem/pkg/cmd/verify"
 	"github.com/gardener/gem/pkg/gem"
 	gemioutil "github.com/gardener/gem/pkg/util/io"
 	"github.com/spf13/cobra"
@@ -31,18 +34,36 @@ func Command(g gem.Interface, streams *gemcmd.Streams) *cobra.Command {
 		controllerRegistrationsFilename string
 		updateAll                       bool
 		updateNames                     []string
+		updateStrategy                  string
+		frozen                          bool
 	)
 
 	cmd := &cobra.Command{
 		Use:   "ensure",
 		Short: "Ensures that the controller registrations and locks are up to date",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return Run(g, streams, requirementsFilename, locksFilename, controllerRegistrationsFilename, updateAll, updateNames)
+			ctx, cancel := gemcmd.Context(cmd)
+			defer cancel()
+
+			profile := gemcmd.Profile(cmd)
+			locksFilename := gemcmd.ProfileFilename(cmd, gemcmd.DefaultLocksFilenameFlag, locksFilename)
+			controllerRegistrationsFilename := gemcmd.ProfileFilename(cmd, gemcmd.DefaultControllerRegistrationsFilenameFlag, controllerRegistrationsFilename)
+
+			if frozen {
+				if updateAll || len(updateNames) > 0 || updateStrategy != "" {
+					return fmt.Errorf("--%s cannot be combined with --%s, --%s or --%s", gemcmd.DefaultFrozenFlag, gemcmd.DefaultUpdateAllFlag, gemcmd.DefaultUpdateFlag, gemcmd.DefaultUpdateStrategyFlag)
+				}
+				return verify.Run(ctx, g, streams, requirementsFilename, profile, locksFilename, controllerRegistrationsFilename)
+			}
+
+			return Run(ctx, g, streams, requirementsFilename, profile, locksFilename, controllerRegistrationsFilename, updateAll, updateNames, updateStrategy)
 		},
 	}
 
 	cmd.Flags().BoolVar(&updateAll, gemcmd.DefaultUpdateAllFlag, gemcmd.DefaultUpdateAll, gemcmd.DefaultUpdateAllUsage)
+	cmd.Flags().BoolVar(&frozen, gemcmd.DefaultFrozenFlag, gemcmd.DefaultFrozen, gemcmd.DefaultFrozenUsage)
 	cmd.Flags().StringSliceVar(&updateNames, gemcmd.DefaultUpdateFlag, gemcmd.DefaultUpdate, gemcmd.DefaultUpdateFlagUsage)
+	cmd.Flags().StringVar(&updateStrategy, gemcmd.DefaultUpdateStrategyFlag, "", gemcmd.DefaultUpdateStrategyUsage)
 	cmd.Flags().StringVar(&requirementsFilename, gemcmd.DefaultRequirementsFilenameFlag, gemcmd.DefaultRequirementsFilename, gemcmd.DefaultRequirementsFilenameUsage)
 	cmd.Flags().StringVar(&locksFilename, gemcmd.DefaultLocksFilenameFlag, gemcmd.DefaultLocksFilename, gemcmd.DefaultLocksFilenameUsage)
 	cmd.Flags().StringVar(&controllerRegistrationsFilename, gemcmd.DefaultControllerRegistrationsFilenameFlag, gemcmd.DefaultControllerRegistrationsFilename, gemcmd.DefaultControllerRegistrationsFilenameUsage)
@@ -50,13 +71,13 @@ func Command(g gem.Interface, streams *gemcmd.Streams) *cobra.Command {
 	return cmd
 }
 
-func Run(g gem.Interface, streams *gemcmd.Streams, requirementsFilename, locksFilename, controllerRegistrationsFilename string, updateAll bool, updateNames []string) error {
-	updatePolicy, err := gemcmd.UpdateFlagsToUpdatePolicy(updateAll, updateNames)
+func Run(ctx context.Context, g gem.Interface, streams *gemcmd.Streams, requirementsFilename, profile, locksFilename, controllerRegistrationsFilename string, updateAll bool, updateNames []string, updateStrategy string) error {
+	updatePolicy, err := gemcmd.UpdateFlagsToUpdatePolicy(updateAll, updateNames, updateStrategy)
 	if err != nil {
 		return err
 	}
 
-	requirements, err := gemcmd.LoadRequirementsFromFileOrReadCloser(requirementsFilename, ioutil.NopCloser(streams.In))
+	requirements, err := gemcmd.LoadProfileRequirementsFromFileOrReadCloser(ctx, g, requirementsFilename, profile, ioutil.NopCloser(streams.In))
 	if err != nil {
 		return err
 	}
@@ -65,17 +86,21 @@ func Run(g gem.Interface, streams *gemcmd.Streams, requirementsFilename, locksFi
 	if err != nil && !os.IsNotExist(err) {
 		return err
 	}
+	locksVersion, err := gemcmd.LocksVersion(locksFilename)
+	if err != nil {
+		return err
+	}
 
-	locks, err = g.Ensure(requirements, locks, updatePolicy)
+	locks, err = g.EnsureContext(ctx, requirements, locks, updatePolicy)
 	if err != nil {
 		return err
 	}
 
-	if err := gemcmd.WriteLocksIntoFileOrWriteCloser(locks, locksFilename, gemioutil.NopWriteCloser(streams.Out)); err != nil {
+	if err := gemcmd.WriteLocksIntoFileOrWriteCloser(locks, locksVersion, locksFilename, gemioutil.NopWriteCloser(streams.Out)); err != nil {
 		return err
 	}
 
-	registrations, err := g.Fetch(requirements, locks)
+	registrations, err := g.FetchContext(ctx, requirements, locks)
 	if err != nil {
 		return err
 	}
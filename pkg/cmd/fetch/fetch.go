@@ -15,6 +15,7 @@
 package fetch
 
 import (
+	"context"
 	"io/ioutil"
 
 	gemioutil "github.com/gardener/gem/pkg/util/io"
@@ -35,7 +36,14 @@ func Command(g gem.Interface, streams *gemcmd.Streams) *cobra.Command {
 		Use:   "fetch",
 		Short: "Fetches the controller registrations specified by the given requirements and locks",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return Run(g, streams, requirementsFilename, locksFilename, controllerRegistrationsFilename)
+			ctx, cancel := gemcmd.Context(cmd)
+			defer cancel()
+
+			profile := gemcmd.Profile(cmd)
+			locksFilename := gemcmd.ProfileFilename(cmd, gemcmd.DefaultLocksFilenameFlag, locksFilename)
+			controllerRegistrationsFilename := gemcmd.ProfileFilename(cmd, gemcmd.DefaultControllerRegistrationsFilenameFlag, controllerRegistrationsFilename)
+
+			return Run(ctx, g, streams, requirementsFilename, profile, locksFilename, controllerRegistrationsFilename)
 		},
 	}
 
@@ -46,8 +54,8 @@ func Command(g gem.Interface, streams *gemcmd.Streams) *cobra.Command {
 	return cmd
 }
 
-func Run(g gem.Interface, streams *gemcmd.Streams, requirementsFilename, locksFilename, controllerRegistrationsFilename string) error {
-	requirements, err := gemcmd.LoadRequirementsFromFileOrReadCloser(requirementsFilename, ioutil.NopCloser(streams.In))
+func Run(ctx context.Context, g gem.Interface, streams *gemcmd.Streams, requirementsFilename, profile, locksFilename, controllerRegistrationsFilename string) error {
+	requirements, err := gemcmd.LoadProfileRequirementsFromFileOrReadCloser(ctx, g, requirementsFilename, profile, ioutil.NopCloser(streams.In))
 	if err != nil {
 		return err
 	}
@@ -57,7 +65,7 @@ func Run(g gem.Interface, streams *gemcmd.Streams, requirementsFilename, locksFi
 		return err
 	}
 
-	registrations, err := g.Fetch(requirements, locks)
+	registrations, err := g.FetchContext(ctx, requirements, locks)
 	if err != nil {
 		return err
 	}
@@ -16,16 +16,31 @@ package cmd
 
 import (
 	gemcmd "github.com/gardener/gem/pkg/cmd"
+	"github.com/gardener/gem/pkg/cmd/add"
+	"github.com/gardener/gem/pkg/cmd/cache"
 	"github.com/gardener/gem/pkg/cmd/ensure"
 	"github.com/gardener/gem/pkg/cmd/fetch"
+	"github.com/gardener/gem/pkg/cmd/migrate"
+	"github.com/gardener/gem/pkg/cmd/outdated"
+	"github.com/gardener/gem/pkg/cmd/remove"
 	"github.com/gardener/gem/pkg/cmd/solve"
+	"github.com/gardener/gem/pkg/cmd/verify"
 	"github.com/gardener/gem/pkg/gem"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
-func Command(g gem.Interface, streams *gemcmd.Streams) *cobra.Command {
-	var level string
+func Command(g gem.Interface, gitCache gem.GitCache, auth gem.CredentialsAuthProvider, streams *gemcmd.Streams) *cobra.Command {
+	var (
+		level               string
+		cacheDir            string
+		credentialsFilename string
+		jobs                int
+		failFast            bool
+		keyring             string
+		allowedSigners      string
+		denyListFilename    string
+	)
 	cmd := &cobra.Command{
 		Use:   "gem",
 		Short: "The Gardener Extension Manager",
@@ -36,17 +51,51 @@ func Command(g gem.Interface, streams *gemcmd.Streams) *cobra.Command {
 			}
 
 			gem.DefaultLogger.SetLevel(logLevel)
-			return nil
+			g.SetJobs(jobs)
+			g.SetFailFast(failFast)
+			gitCache.SetDir(cacheDir)
+
+			verifier, err := gemcmd.LoadSignatureVerifier(keyring, allowedSigners)
+			if err != nil {
+				return err
+			}
+			if verifier != nil {
+				g.SetSignatureVerifier(verifier)
+			}
+
+			denyList, err := gemcmd.LoadDenyList(denyListFilename)
+			if err != nil {
+				return err
+			}
+			if denyList != nil {
+				g.SetDenyList(denyList)
+			}
+			return gemcmd.LoadCredentialsIntoAuthProvider(auth, credentialsFilename, cmd.Flags().Changed(gemcmd.DefaultCredentialsFilenameFlag))
 		},
 		SilenceUsage: true,
 	}
 
 	cmd.PersistentFlags().StringVarP(&level, gemcmd.DefaultLogLevelFlag, gemcmd.DefaultLogLevelFlagP, gemcmd.DefaultLogLevel, gemcmd.DefaultLogLevelUsage)
+	cmd.PersistentFlags().String(gemcmd.DefaultProfileFlag, "", gemcmd.DefaultProfileUsage)
+	cmd.PersistentFlags().IntVarP(&jobs, gemcmd.DefaultJobsFlag, gemcmd.DefaultJobsFlagP, g.Jobs(), gemcmd.DefaultJobsUsage)
+	cmd.PersistentFlags().BoolVar(&failFast, gemcmd.DefaultFailFastFlag, gemcmd.DefaultFailFast, gemcmd.DefaultFailFastUsage)
+	cmd.PersistentFlags().Duration(gemcmd.DefaultTimeoutFlag, gemcmd.DefaultTimeout, gemcmd.DefaultTimeoutUsage)
+	cmd.PersistentFlags().StringVar(&cacheDir, gemcmd.DefaultCacheDirFlag, gitCache.Dir(), gemcmd.DefaultCacheDirUsage)
+	cmd.PersistentFlags().StringVar(&credentialsFilename, gemcmd.DefaultCredentialsFilenameFlag, gem.DefaultCredentialsFile(), gemcmd.DefaultCredentialsFilenameUsage)
+	cmd.PersistentFlags().StringVar(&keyring, gemcmd.DefaultKeyringFlag, "", gemcmd.DefaultKeyringUsage)
+	cmd.PersistentFlags().StringVar(&allowedSigners, gemcmd.DefaultAllowedSignersFlag, "", gemcmd.DefaultAllowedSignersUsage)
+	cmd.PersistentFlags().StringVar(&denyListFilename, gemcmd.DefaultDenyListFlag, "", gemcmd.DefaultDenyListUsage)
 
 	cmd.AddCommand(
 		solve.Command(g, streams),
 		fetch.Command(g, streams),
 		ensure.Command(g, streams),
+		outdated.Command(g, streams),
+		verify.Command(g, streams),
+		add.Command(g, streams),
+		remove.Command(g, streams),
+		migrate.Command(streams),
+		cache.Command(gitCache, streams),
 	)
 
 	return cmd
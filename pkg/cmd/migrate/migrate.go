@@ -0,0 +1,77 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	"io/ioutil"
+	"os"
+
+	gemcmd "github.com/gardener/gem/pkg/cmd"
+	gemapilatest "github.com/gardener/gem/pkg/gem/api/latest"
+	gemioutil "github.com/gardener/gem/pkg/util/io"
+	"github.com/spf13/cobra"
+)
+
+func Command(streams *gemcmd.Streams) *cobra.Command {
+	var (
+		requirementsFilename string
+		locksFilename        string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Rewrites the requirements and locks files in the latest API version",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			locksRequired := cmd.Flags().Changed(gemcmd.DefaultLocksFilenameFlag)
+			locksFilename := gemcmd.ProfileFilename(cmd, gemcmd.DefaultLocksFilenameFlag, locksFilename)
+
+			return Run(streams, requirementsFilename, locksFilename, locksRequired)
+		},
+	}
+
+	cmd.Flags().StringVar(&requirementsFilename, gemcmd.DefaultRequirementsFilenameFlag, gemcmd.DefaultRequirementsFilename, gemcmd.DefaultRequirementsFilenameUsage)
+	cmd.Flags().StringVar(&locksFilename, gemcmd.DefaultLocksFilenameFlag, gemcmd.DefaultLocksFilename, gemcmd.DefaultLocksFilenameUsage)
+
+	return cmd
+}
+
+// Run rewrites the given requirements and locks files in place. Includes are neither resolved nor migrated.
+// An empty filename skips the file, a missing locks file is only an error if locksRequired is set.
+func Run(streams *gemcmd.Streams, requirementsFilename, locksFilename string, locksRequired bool) error {
+	if requirementsFilename != "" {
+		requirements, err := gemcmd.LoadRequirementsFromFileOrReadCloser(requirementsFilename, ioutil.NopCloser(streams.In))
+		if err != nil {
+			return err
+		}
+
+		if err := gemcmd.WriteRequirementsIntoFileOrWriteCloser(requirements, requirementsFilename, gemioutil.NopWriteCloser(streams.Out)); err != nil {
+			return err
+		}
+	}
+
+	if locksFilename == "" {
+		return nil
+	}
+
+	locks, err := gemcmd.LoadLocksFromFileOrReadCloser(locksFilename, ioutil.NopCloser(streams.In))
+	if err != nil {
+		if os.IsNotExist(err) && !locksRequired {
+			return nil
+		}
+		return err
+	}
+
+	return gemcmd.WriteLocksIntoFileOrWriteCloser(locks, gemapilatest.GroupVersion, locksFilename, gemioutil.NopWriteCloser(streams.Out))
+}
@@ -0,0 +1,178 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outdated
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"text/tabwriter"
+
+	gemcmd "github.com/gardener/gem/pkg/cmd"
+	"github.com/gardener/gem/pkg/gem"
+	gemapi "github.com/gardener/gem/pkg/gem/api"
+	"github.com/spf13/cobra"
+)
+
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+
+	shortHashLength = 12
+)
+
+func Command(g gem.Interface, streams *gemcmd.Streams) *cobra.Command {
+	var (
+		requirementsFilename string
+		locksFilename        string
+		output               string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "outdated",
+		Short: "Shows the locked, wanted and latest versions of all extensions",
+		Long: `Shows the locked, wanted and latest versions of all extensions.
+
+The wanted version is the newest version satisfying the requirement, the latest
+version is the newest version available and the prerelease column shows a
+prerelease that is newer than the latest version, if any.
+
+Exits with code 2 if the locked version of any extension differs from the
+wanted one and with code 1 if the versions could not be determined.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := gemcmd.Context(cmd)
+			defer cancel()
+
+			profile := gemcmd.Profile(cmd)
+			locksFilename := gemcmd.ProfileFilename(cmd, gemcmd.DefaultLocksFilenameFlag, locksFilename)
+
+			return Run(ctx, g, streams, requirementsFilename, profile, locksFilename, output)
+		},
+	}
+
+	cmd.Flags().StringVar(&requirementsFilename, gemcmd.DefaultRequirementsFilenameFlag, gemcmd.DefaultRequirementsFilename, gemcmd.DefaultRequirementsFilenameUsage)
+	cmd.Flags().StringVar(&locksFilename, gemcmd.DefaultLocksFilenameFlag, gemcmd.DefaultLocksFilename, gemcmd.DefaultLocksFilenameUsage)
+	cmd.Flags().StringVarP(&output, gemcmd.DefaultOutputFlag, gemcmd.DefaultOutputFlagP, OutputTable, fmt.Sprintf("Output format, one of %s, %s", OutputTable, OutputJSON))
+
+	return cmd
+}
+
+func Run(ctx context.Context, g gem.Interface, streams *gemcmd.Streams, requirementsFilename, profile, locksFilename, output string) error {
+	if output != OutputTable && output != OutputJSON {
+		return fmt.Errorf("invalid output format %q", output)
+	}
+
+	requirements, err := gemcmd.LoadProfileRequirementsFromFileOrReadCloser(ctx, g, requirementsFilename, profile, ioutil.NopCloser(streams.In))
+	if err != nil {
+		return err
+	}
+
+	locks, err := gem.LoadLocksFromFile(locksFilename)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	statuses, err := g.OutdatedContext(ctx, requirements, locks)
+	if statuses != nil {
+		if err := write(streams, output, statuses); err != nil {
+			return err
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	var outdated int
+	for _, status := range statuses {
+		if status.Outdated() {
+			outdated++
+		}
+	}
+	if outdated > 0 {
+		return &gemcmd.ExitError{Code: gemcmd.ExitCodeOutdated, Err: fmt.Errorf("%d of %d extensions are outdated", outdated, len(statuses))}
+	}
+	return nil
+}
+
+type jsonStatus struct {
+	Name       string `json:"name"`
+	Target     string `json:"target"`
+	Locked     string `json:"locked,omitempty"`
+	Wanted     string `json:"wanted"`
+	Latest     string `json:"latest"`
+	Prerelease string `json:"prerelease,omitempty"`
+	Outdated   bool   `json:"outdated"`
+}
+
+func write(streams *gemcmd.Streams, output string, statuses []gem.ModuleStatus) error {
+	if output == OutputJSON {
+		out := make([]jsonStatus, 0, len(statuses))
+		for _, status := range statuses {
+			out = append(out, jsonStatus{
+				Name:       status.ModuleKey.String(),
+				Target:     status.Target.String(),
+				Locked:     status.Locked,
+				Wanted:     status.Wanted,
+				Latest:     status.Latest,
+				Prerelease: status.Prerelease,
+				Outdated:   status.Outdated(),
+			})
+		}
+
+		enc := json.NewEncoder(streams.Out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+
+	w := tabwriter.NewWriter(streams.Out, 0, 8, 2, ' ', 0)
+	if _, err := fmt.Fprintln(w, "NAME\tTARGET\tLOCKED\tWANTED\tLATEST\tPRERELEASE"); err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		locked := status.Locked
+		if locked == "" {
+			locked = "-"
+		}
+		latest := status.Latest
+		if latest == "" {
+			latest = "-"
+		}
+		prerelease := status.Prerelease
+		if prerelease == "" {
+			prerelease = "-"
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			status.ModuleKey.String(),
+			status.Target.String(),
+			shortVersion(status.Target, locked),
+			shortVersion(status.Target, status.Wanted),
+			shortVersion(status.Target, latest),
+			prerelease,
+		); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// shortVersion abbreviates commit hashes of targets that are not resolved to versions.
+func shortVersion(target gemapi.Target, version string) string {
+	if target.Type == gemapi.Version || len(version) <= shortHashLength {
+		return version
+	}
+	return version[:shortHashLength]
+}
@@ -0,0 +1,81 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remove
+
+import (
+	"context"
+	"fmt"
+
+	gemcmd "github.com/gardener/gem/pkg/cmd"
+	"github.com/gardener/gem/pkg/cmd/ensure"
+	"github.com/gardener/gem/pkg/gem"
+	gemapi "github.com/gardener/gem/pkg/gem/api"
+	"github.com/spf13/cobra"
+)
+
+func Command(g gem.Interface, streams *gemcmd.Streams) *cobra.Command {
+	var (
+		requirementsFilename            string
+		locksFilename                   string
+		controllerRegistrationsFilename string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "remove NAME...",
+		Short: "Removes requirements from the requirements file and ensures the locks and controller registrations",
+		Long: `Removes requirements from the requirements file and ensures the locks and controller registrations.
+Only the requirements of the file itself are removed, neither the ones of included files nor the ones of profiles.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := gemcmd.Context(cmd)
+			defer cancel()
+
+			if profile := gemcmd.Profile(cmd); profile != "" {
+				return fmt.Errorf("--%s is not supported by %s, edit the requirements of profile %s in %s instead", gemcmd.DefaultProfileFlag, cmd.Name(), profile, requirementsFilename)
+			}
+
+			return Run(ctx, g, streams, requirementsFilename, locksFilename, controllerRegistrationsFilename, args)
+		},
+	}
+
+	cmd.Flags().StringVar(&requirementsFilename, gemcmd.DefaultRequirementsFilenameFlag, gemcmd.DefaultRequirementsFilename, gemcmd.DefaultRequirementsFilenameUsage)
+	cmd.Flags().StringVar(&locksFilename, gemcmd.DefaultLocksFilenameFlag, gemcmd.DefaultLocksFilename, gemcmd.DefaultLocksFilenameUsage)
+	cmd.Flags().StringVar(&controllerRegistrationsFilename, gemcmd.DefaultControllerRegistrationsFilenameFlag, gemcmd.DefaultControllerRegistrationsFilename, gemcmd.DefaultControllerRegistrationsFilenameUsage)
+
+	return cmd
+}
+
+func Run(ctx context.Context, g gem.Interface, streams *gemcmd.Streams, requirementsFilename, locksFilename, controllerRegistrationsFilename string, names []string) error {
+	var moduleKeys []gemapi.ModuleKey
+	for _, name := range names {
+		moduleKey, err := gemcmd.ParseModuleName(name)
+		if err != nil {
+			return err
+		}
+		moduleKeys = append(moduleKeys, moduleKey)
+	}
+
+	return gemcmd.EditRequirementsFile(requirementsFilename, []string{locksFilename, controllerRegistrationsFilename}, func(data []byte) ([]byte, error) {
+		for _, moduleKey := range moduleKeys {
+			var err error
+			if data, err = gem.RemoveRequirement(data, moduleKey); err != nil {
+				return nil, err
+			}
+		}
+		return data, nil
+	}, func() error {
+		return ensure.Run(ctx, g, streams, requirementsFilename, "", locksFilename, controllerRegistrationsFilename, false, nil, "")
+	})
+}
@@ -15,6 +15,7 @@
 package solve
 
 import (
+	"context"
 	"io/ioutil"
 
 	gemioutil "github.com/gardener/gem/pkg/util/io"
@@ -34,7 +35,13 @@ func Command(g gem.Interface, streams *gemcmd.Streams) *cobra.Command {
 		Use:   "solve",
 		Short: "Resolves the requirements in the requirements file and writes locks",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return Run(g, streams, requirementsFilename, locksFilename)
+			ctx, cancel := gemcmd.Context(cmd)
+			defer cancel()
+
+			profile := gemcmd.Profile(cmd)
+			locksFilename := gemcmd.ProfileFilename(cmd, gemcmd.DefaultLocksFilenameFlag, locksFilename)
+
+			return Run(ctx, g, streams, requirementsFilename, profile, locksFilename)
 		},
 	}
 
@@ -44,16 +51,21 @@ func Command(g gem.Interface, streams *gemcmd.Streams) *cobra.Command {
 	return cmd
 }
 
-func Run(g gem.Interface, streams *gemcmd.Streams, requirementsFilename, locksFilename string) error {
-	requirements, err := gemcmd.LoadRequirementsFromFileOrReadCloser(requirementsFilename, ioutil.NopCloser(streams.In))
+func Run(ctx context.Context, g gem.Interface, streams *gemcmd.Streams, requirementsFilename, profile, locksFilename string) error {
+	requirements, err := gemcmd.LoadProfileRequirementsFromFileOrReadCloser(ctx, g, requirementsFilename, profile, ioutil.NopCloser(streams.In))
+	if err != nil {
+		return err
+	}
+
+	locksVersion, err := gemcmd.LocksVersion(locksFilename)
 	if err != nil {
 		return err
 	}
 
-	locks, err := g.Solve(requirements)
+	locks, err := g.SolveContext(ctx, requirements)
 	if err != nil {
 		return err
 	}
 
-	return gemcmd.WriteLocksIntoFileOrWriteCloser(locks, locksFilename, gemioutil.NopWriteCloser(streams.Out))
+	return gemcmd.WriteLocksIntoFileOrWriteCloser(locks, locksVersion, locksFilename, gemioutil.NopWriteCloser(streams.Out))
 }
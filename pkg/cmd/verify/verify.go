@@ -0,0 +1,98 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	gemcmd "github.com/gardener/gem/pkg/cmd"
+	"github.com/gardener/gem/pkg/gem"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func Command(g gem.Interface, streams *gemcmd.Streams) *cobra.Command {
+	var (
+		requirementsFilename            string
+		locksFilename                   string
+		controllerRegistrationsFilename string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verifies that the locks and controller registrations are up to date without modifying them",
+		Long: `Verifies that the locks and controller registrations are up to date without modifying them.
+
+Fails if an extension has no lock, its lock does not satisfy the requirement, the
+registration file does not exist at the locked hash or the controller registrations
+differ from what fetch would write.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := gemcmd.Context(cmd)
+			defer cancel()
+
+			profile := gemcmd.Profile(cmd)
+			locksFilename := gemcmd.ProfileFilename(cmd, gemcmd.DefaultLocksFilenameFlag, locksFilename)
+			controllerRegistrationsFilename := gemcmd.ProfileFilename(cmd, gemcmd.DefaultControllerRegistrationsFilenameFlag, controllerRegistrationsFilename)
+
+			return Run(ctx, g, streams, requirementsFilename, profile, locksFilename, controllerRegistrationsFilename)
+		},
+	}
+
+	cmd.Flags().StringVar(&requirementsFilename, gemcmd.DefaultRequirementsFilenameFlag, gemcmd.DefaultRequirementsFilename, gemcmd.DefaultRequirementsFilenameUsage)
+	cmd.Flags().StringVar(&locksFilename, gemcmd.DefaultLocksFilenameFlag, gemcmd.DefaultLocksFilename, gemcmd.DefaultLocksFilenameUsage)
+	cmd.Flags().StringVar(&controllerRegistrationsFilename, gemcmd.DefaultControllerRegistrationsFilenameFlag, gemcmd.DefaultControllerRegistrationsFilename, gemcmd.DefaultControllerRegistrationsFilenameUsage)
+
+	return cmd
+}
+
+func loadControllerRegistrations(filename string) ([]runtime.Object, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []runtime.Object{}, nil
+		}
+		return nil, err
+	}
+
+	return gem.LoadControllerRegistrations(bytes.NewReader(data))
+}
+
+func Run(ctx context.Context, g gem.Interface, streams *gemcmd.Streams, requirementsFilename, profile, locksFilename, controllerRegistrationsFilename string) error {
+	requirements, err := gemcmd.LoadProfileRequirementsFromFileOrReadCloser(ctx, g, requirementsFilename, profile, ioutil.NopCloser(streams.In))
+	if err != nil {
+		return err
+	}
+
+	locks, err := gem.LoadLocksFromFile(locksFilename)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	registrations, err := loadControllerRegistrations(controllerRegistrationsFilename)
+	if err != nil {
+		return fmt.Errorf("could not load controller registrations from %s: %w", controllerRegistrationsFilename, err)
+	}
+
+	if err := g.VerifyContext(ctx, requirements, locks, registrations); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(streams.Err, "Verified %d extensions\n", len(requirements.Requirements))
+	return err
+}
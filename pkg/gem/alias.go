@@ -15,10 +15,28 @@
 package gem
 
 var (
+	// ResolveIncludes is an alias for `Default.ResolveIncludes`.
+	ResolveIncludes = Default.ResolveIncludes
 	// Solve is an alias for `Default.Solve`.
 	Solve = Default.Solve
 	// Fetch is an alias for `Default.Fetch`.
 	Fetch = Default.Fetch
 	// Ensure is an alias for `Default.Ensure`.
 	Ensure = Default.Ensure
+	// Outdated is an alias for `Default.Outdated`.
+	Outdated = Default.Outdated
+	// Verify is an alias for `Default.Verify`.
+	Verify = Default.Verify
+	// ResolveIncludesContext is an alias for `Default.ResolveIncludesContext`.
+	ResolveIncludesContext = Default.ResolveIncludesContext
+	// SolveContext is an alias for `Default.SolveContext`.
+	SolveContext = Default.SolveContext
+	// FetchContext is an alias for `Default.FetchContext`.
+	FetchContext = Default.FetchContext
+	// EnsureContext is an alias for `Default.EnsureContext`.
+	EnsureContext = Default.EnsureContext
+	// OutdatedContext is an alias for `Default.OutdatedContext`.
+	OutdatedContext = Default.OutdatedContext
+	// VerifyContext is an alias for `Default.VerifyContext`.
+	VerifyContext = Default.VerifyContext
 )
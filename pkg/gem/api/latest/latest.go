@@ -17,6 +17,7 @@ package latest
 import (
 	"github.com/gardener/gem/pkg/gem/api"
 	"github.com/gardener/gem/pkg/gem/api/v1alpha1"
+	"github.com/gardener/gem/pkg/gem/api/v1alpha2"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer/json"
@@ -25,9 +26,12 @@ import (
 )
 
 // Version is the string that represents the current external default version.
-const Version = "v1alpha1"
+const Version = "v1alpha2"
 
 var (
+	// GroupVersion is the current external default group version.
+	GroupVersion = schema.GroupVersion{Group: "gem.gardener.cloud", Version: Version}
+
 	Codec  runtime.Codec
 	Scheme *runtime.Scheme
 )
@@ -36,12 +40,18 @@ func init() {
 	Scheme = runtime.NewScheme()
 	utilruntime.Must(api.AddToScheme(Scheme))
 	utilruntime.Must(v1alpha1.AddToScheme(Scheme))
+	utilruntime.Must(v1alpha2.AddToScheme(Scheme))
+	Codec = NewCodec(GroupVersion)
+}
+
+// NewCodec returns a YAML codec that encodes into the given version.
+func NewCodec(gv schema.GroupVersion) runtime.Codec {
 	yamlSerializer := json.NewYAMLSerializer(json.DefaultMetaFactory, Scheme, Scheme)
-	Codec = versioning.NewDefaultingCodecForScheme(
+	return versioning.NewDefaultingCodecForScheme(
 		Scheme,
 		yamlSerializer,
 		yamlSerializer,
-		schema.GroupVersion{Group: "gem.gardener.cloud", Version: Version},
+		gv,
 		runtime.InternalGroupVersioner,
 	)
 }
@@ -30,6 +30,9 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&Requirements{},
 		&Locks{},
+		&Credentials{},
+		&DenyList{},
+		&Constraints{},
 	)
 	return nil
 }
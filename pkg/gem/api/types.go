@@ -16,6 +16,8 @@ package api
 
 import (
 	"fmt"
+	"regexp"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -39,11 +41,95 @@ type Target struct {
 	Revision string
 	Version  string
 	Branch   string
+	// TagPrefix restricts a Version target to the tags starting with the prefix, e.g. `provider-aws/`.
+	TagPrefix string
+	// Prereleases controls whether a Version target may resolve to a prerelease. If empty, prereleases only
+	// match constraints that contain a prerelease themselves.
+	Prereleases PrereleasePolicy
+	// Exclude are the versions a Version target must never resolve to, even if they satisfy it. They are not
+	// recorded in locks.
+	Exclude []ExcludedVersion
+}
+
+// PrereleasePolicy controls whether prerelease versions, e.g. `1.2.0-rc.1`, are candidates for a version target.
+type PrereleasePolicy string
+
+const (
+	// PrereleasesExclude never resolves to a prerelease, even if the constraint contains one.
+	PrereleasesExclude PrereleasePolicy = "exclude"
+	// PrereleasesInclude treats prereleases like releases. A prerelease matches the constraint if its release
+	// version does, e.g. `1.3.0-rc.1` matches `^1.2.0`.
+	PrereleasesInclude PrereleasePolicy = "include"
+	// PrereleasesOnlyIfNewer treats prereleases like PrereleasesInclude, but only if they are newer than all
+	// releases of the repository that satisfy the version range, i.e. previews of the next release in the
+	// range rather than of a maintenance release.
+	PrereleasesOnlyIfNewer PrereleasePolicy = "only-if-newer"
+)
+
+// PrereleasePolicies are all supported prerelease policies.
+var PrereleasePolicies = []PrereleasePolicy{PrereleasesExclude, PrereleasesInclude, PrereleasesOnlyIfNewer}
+
+// ParsePrereleasePolicy parses the given prerelease policy, failing for unsupported ones.
+func ParsePrereleasePolicy(s string) (PrereleasePolicy, error) {
+	for _, policy := range PrereleasePolicies {
+		if string(policy) == s {
+			return policy, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported prerelease policy %q, supported are %v", s, PrereleasePolicies)
 }
 
 type Requirement struct {
 	Target   Target
 	Filename string
+	// URL is the URL the repository is cloned from. If empty, the repository itself is used as URL.
+	URL string
+	// SubmoduleTagPrefix derives the tag prefix of the target from the submodule, e.g. `provider-aws/`.
+	SubmoduleTagPrefix bool
+	// UpdateStrategy limits how far an update may move the lock of a version target. If empty,
+	// UpdateStrategyLatestInRange is used.
+	UpdateStrategy UpdateStrategy
+	// Requires are the version ranges other modules have to satisfy, in addition to the ones declared by the module.
+	Requires map[ModuleKey]string
+	// Gardener is the version range of Gardener the module works with, in addition to the one declared by the
+	// module. If empty, any version is.
+	Gardener string
+}
+
+// ExcludedVersion is a version that must never be resolved, e.g. because it is broken or was yanked.
+type ExcludedVersion struct {
+	// Version is the excluded version without tag prefix, e.g. `1.4.2`.
+	Version string
+	// Reason explains why the version is excluded. It is empty if unknown.
+	Reason string
+}
+
+// UpdateStrategy limits how far an update may move a version lock relative to the previously locked version.
+type UpdateStrategy string
+
+const (
+	// UpdateStrategyLatestInRange updates to the greatest version satisfying the requirement.
+	UpdateStrategyLatestInRange UpdateStrategy = "latest-in-range"
+	// UpdateStrategyMajor updates to the greatest version satisfying the requirement that is not lower than the
+	// locked version.
+	UpdateStrategyMajor UpdateStrategy = "major"
+	// UpdateStrategyMinor updates to the greatest version of the same major version as the locked version.
+	UpdateStrategyMinor UpdateStrategy = "minor"
+	// UpdateStrategyPatch updates to the greatest version of the same major and minor version as the locked version.
+	UpdateStrategyPatch UpdateStrategy = "patch"
+)
+
+// UpdateStrategies are all supported update strategies.
+var UpdateStrategies = []UpdateStrategy{UpdateStrategyPatch, UpdateStrategyMinor, UpdateStrategyMajor, UpdateStrategyLatestInRange}
+
+// ParseUpdateStrategy parses the given update strategy, failing for unsupported ones.
+func ParseUpdateStrategy(s string) (UpdateStrategy, error) {
+	for _, strategy := range UpdateStrategies {
+		if string(strategy) == s {
+			return strategy, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported update strategy %q, supported are %v", s, UpdateStrategies)
 }
 
 // +kubebuilder:object:root=true
@@ -53,6 +139,46 @@ type Requirements struct {
 	metav1.TypeMeta `json:",inline"`
 
 	Requirements map[ModuleKey]*Requirement
+	// Prereleases is the prerelease policy of all version requirements of this file (including the ones of its
+	// profiles) that do not specify one themselves. It does not apply to the requirements of includes.
+	Prereleases PrereleasePolicy
+	// Includes are other requirements files that are merged into these requirements, in order. Requirements of
+	// later includes replace the ones of earlier includes, the own requirements replace all included ones.
+	Includes []Include
+	// AllowedOverlaps are the resources that may be registered as primary by more than one extension.
+	AllowedOverlaps []ResourceOverlap
+	// Profiles are named overlays of the requirements, e.g. for different landscapes.
+	Profiles map[string]*Profile
+	// GardenerVersion is the version of Gardener the extensions are deployed to. If empty, the Gardener version
+	// ranges of the modules are not checked.
+	GardenerVersion string
+}
+
+// Include refers to a requirements file, either relative to the including file or in a repository.
+type Include struct {
+	// Repository is the repository containing the file. If empty, Path is relative to the including file.
+	Repository string
+	// Revision is the full commit hash of the repository the file is read at.
+	Revision string
+	Path     string
+}
+
+// Profile overlays the requirements it is part of.
+type Profile struct {
+	// Requirements are added to the base requirements, replacing the base requirements of the same module.
+	Requirements map[ModuleKey]*Requirement
+	// Exclude are the modules whose base requirements are removed.
+	Exclude []ModuleKey
+	// AllowedOverlaps are allowed in addition to the allowed overlaps of the base requirements.
+	AllowedOverlaps []ResourceOverlap
+	// GardenerVersion replaces the Gardener version of the base requirements, if set.
+	GardenerVersion string
+}
+
+// ResourceOverlap is a kind/type combination of an extension resource, e.g. `Infrastructure/aws`.
+type ResourceOverlap struct {
+	Kind string
+	Type string
 }
 
 // +kubebuilder:object:root=true
@@ -61,13 +187,116 @@ type Requirements struct {
 type Locks struct {
 	metav1.TypeMeta
 
-	Locks map[ModuleKey]*Lock
+	Header LocksHeader
+	Locks  map[ModuleKey]*Lock
+}
+
+// LocksHeader describes how the locks were resolved.
+type LocksHeader struct {
+	// RequirementsDigest is the digest of the requirements the locks were resolved from, e.g. `sha256:<hex>`.
+	RequirementsDigest string
+	// GemVersion is the version of gem that resolved the locks.
+	GemVersion string
 }
 
 type Lock struct {
-	Hash     string
-	Target   Target
-	Resolved Target
+	// Hash is the hash of the resolved commit.
+	Hash string
+	// TagHash is the hash of the annotated tag object the lock was resolved from, if any.
+	TagHash string
+	// Digest is the digest of the registration file at the resolved commit, e.g. `sha256:<hex>`.
+	Digest string
+	// Signer is the identity of the trusted signer of the resolved tag or commit, if signatures are verified.
+	Signer string
+	// CommitTime is the time the resolved commit was committed.
+	CommitTime time.Time
+	// Subject is the subject of the message of the resolved annotated tag or, if there is none, of the commit.
+	Subject string
+	// ResolvedAt is the time the lock was resolved.
+	ResolvedAt time.Time
+	// GemVersion is the version of gem that resolved the lock.
+	GemVersion string
+	Target     Target
+	Resolved   Target
+}
+
+// +kubebuilder:object:root=true
+
+// Constraints are declared by a module in its repository, next to its registration file. They restrict the
+// versions of other modules and of Gardener the module works with.
+type Constraints struct {
+	metav1.TypeMeta
+
+	// Requires are the version ranges other modules have to satisfy, e.g. `>=1.3.0`.
+	Requires map[ModuleKey]string
+	// Gardener is the version range of Gardener the module works with. If empty, any version is.
+	Gardener string
+}
+
+// +kubebuilder:object:root=true
+
+// DenyList lists versions of modules that must never be resolved, regardless of their requirements.
+type DenyList struct {
+	metav1.TypeMeta
+
+	// Denied are the denied versions of each module.
+	Denied map[ModuleKey][]ExcludedVersion
+}
+
+// +kubebuilder:object:root=true
+
+// Credentials configures how to authenticate against the hosts of repositories.
+type Credentials struct {
+	metav1.TypeMeta
+
+	// Credentials are checked in order, the first one whose host pattern matches is used.
+	Credentials []HostCredential
+}
+
+// HostCredential is a credential for all repositories whose host (and optionally path) match the Host pattern.
+// Exactly one of the credential sources has to be set.
+type HostCredential struct {
+	Host string
+
+	SSHAgent *SSHAgentCredential
+	SSHKey   *SSHKeyCredential
+	Basic    *BasicCredential
+	Token    *TokenCredential
+	Netrc    *NetrcCredential
+	Helper   *CredentialHelper
+}
+
+// SSHAgentCredential authenticates via the running SSH agent.
+type SSHAgentCredential struct {
+	User string
+}
+
+// SSHKeyCredential authenticates via a private key file.
+type SSHKeyCredential struct {
+	User          string
+	Path          string
+	PassphraseEnv string
+}
+
+// BasicCredential authenticates via HTTP basic auth with username and password read from the environment.
+type BasicCredential struct {
+	UsernameEnv string
+	PasswordEnv string
+}
+
+// TokenCredential authenticates via an HTTP bearer token read from the environment.
+type TokenCredential struct {
+	TokenEnv string
+}
+
+// NetrcCredential authenticates via HTTP basic auth with the login of a .netrc file.
+type NetrcCredential struct {
+	Path string
+}
+
+// CredentialHelper authenticates via HTTP basic auth with the credentials obtained from `git credential fill`.
+type CredentialHelper struct {
+	Helper string
 }
 
 func NewRequirement() *Requirement {
@@ -89,6 +318,16 @@ func (m *ModuleKey) String() string {
 	return fmt.Sprintf("%s/%s", m.Repository, m.Submodule)
 }
 
+// Equal reports whether both targets are the same, disregarding their excluded versions.
+func (t *Target) Equal(o *Target) bool {
+	return t.Type == o.Type &&
+		t.Revision == o.Revision &&
+		t.Version == o.Version &&
+		t.Branch == o.Branch &&
+		t.TagPrefix == o.TagPrefix &&
+		t.Prereleases == o.Prereleases
+}
+
 func (t *Target) String() string {
 	switch t.Type {
 	case Latest:
@@ -96,7 +335,7 @@ func (t *Target) String() string {
 	case Revision:
 		return fmt.Sprintf("revision/%s", t.Revision)
 	case Version:
-		return fmt.Sprintf("version/%s", t.Version)
+		return fmt.Sprintf("version/%s%s", t.TagPrefix, t.Version)
 	case Branch:
 		return fmt.Sprintf("branch/%s", t.Branch)
 	default:
@@ -104,6 +343,25 @@ func (t *Target) String() string {
 	}
 }
 
+var commitHashRegex = regexp.MustCompile(`^[0-9a-fA-F]{40}$`)
+
+// IsCommitHash checks whether the given revision is a full commit hash. Tags, branches and
+// abbreviated hashes are not.
+func IsCommitHash(revision string) bool {
+	return commitHashRegex.MatchString(revision)
+}
+
+func (i *Include) String() string {
+	if i.Repository == "" {
+		return i.Path
+	}
+	return fmt.Sprintf("%s@%s:%s", i.Repository, i.Revision, i.Path)
+}
+
+func (r *ResourceOverlap) String() string {
+	return fmt.Sprintf("%s/%s", r.Kind, r.Type)
+}
+
 func (l *Lock) String() string {
 	return fmt.Sprintf("%v:%s", &l.Resolved, l.Hash)
 }
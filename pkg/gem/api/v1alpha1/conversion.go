@@ -17,16 +17,24 @@ package v1alpha1
 import (
 	"fmt"
 	"regexp"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/Masterminds/semver"
 	"github.com/gardener/gem/pkg/util/pointer"
 
 	"github.com/gardener/gem/pkg/gem/api"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/conversion"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
-const DefaultRequirementFilename = "controller-registration.yaml"
+const (
+	DefaultRequirementFilename = "controller-registration.yaml"
+	DefaultSSHUser             = "git"
+)
 
 func emptyStringOrString(s *string) string {
 	if s == nil {
@@ -35,6 +43,20 @@ func emptyStringOrString(s *string) string {
 	return *s
 }
 
+func timeOrZero(t *metav1.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return t.Time
+}
+
+func nilOrTime(t time.Time) *metav1.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &metav1.Time{Time: t}
+}
+
 func nilOrString(s string) *string {
 	if s == "" {
 		return nil
@@ -56,10 +78,29 @@ func ExtractModuleKeyFromName(name string) (api.ModuleKey, error) {
 	return api.ModuleKey{Repository: parts[1], Submodule: parts[3]}, nil
 }
 
+// NormalizeTagPrefix makes sure a non-empty tag prefix ends with exactly one slash.
+func NormalizeTagPrefix(prefix string) string {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return ""
+	}
+	return prefix + "/"
+}
+
 func ModuleKeyToName(key *api.ModuleKey) string {
 	return key.String()
 }
 
+// moduleKeyToName converts the module key into a name from which the same module key is extracted again.
+// Module keys whose repository does not consist of exactly three path segments cannot be represented.
+func moduleKeyToName(key *api.ModuleKey) (string, error) {
+	name := ModuleKeyToName(key)
+	if extracted, err := ExtractModuleKeyFromName(name); err != nil || extracted != *key {
+		return "", fmt.Errorf("module %s cannot be represented in %s, repository and submodule would be extracted wrongly", name, SchemeGroupVersion)
+	}
+	return name, nil
+}
+
 func Convert_v1alpha1_Target_To_gem_Target(in *Target, out *api.Target, s conversion.Scope) error {
 	ct := 0
 	var (
@@ -88,30 +129,129 @@ func Convert_v1alpha1_Target_To_gem_Target(in *Target, out *api.Target, s conver
 	if ct > 1 {
 		return fmt.Errorf("error converting %T into %T: more than one target definition is not allowed", in, out)
 	}
+	if in.TagPrefix != nil && targetType != api.Version {
+		return fmt.Errorf("error converting %T into %T: a tag prefix is only allowed for versions", in, out)
+	}
+	if in.Prereleases != nil && targetType != api.Version {
+		return fmt.Errorf("error converting %T into %T: a prerelease policy is only allowed for versions", in, out)
+	}
+	prereleases, err := convertPrereleasePolicyToInternal(in.Prereleases)
+	if err != nil {
+		return fmt.Errorf("error converting %T into %T: %w", in, out, err)
+	}
 	*out = api.Target{
-		Type:     targetType,
-		Version:  version,
-		Revision: revision,
-		Branch:   branch,
+		Type:        targetType,
+		Version:     version,
+		Revision:    revision,
+		Branch:      branch,
+		TagPrefix:   NormalizeTagPrefix(emptyStringOrString(in.TagPrefix)),
+		Prereleases: prereleases,
 	}
 	return nil
 }
 
 func Convert_gem_Target_To_v1alpha1_Target(in *api.Target, out *Target, s conversion.Scope) error {
 	*out = Target{
-		Version:  nilOrString(in.Version),
-		Revision: nilOrString(in.Revision),
-		Branch:   nilOrString(in.Branch),
+		Version:     nilOrString(in.Version),
+		Revision:    nilOrString(in.Revision),
+		Branch:      nilOrString(in.Branch),
+		TagPrefix:   nilOrString(in.TagPrefix),
+		Prereleases: nilOrString(string(in.Prereleases)),
 	}
 	return nil
 }
 
+// convertExcludedVersionsToInternal validates the given versions and excludes them for the given reason.
+func convertExcludedVersionsToInternal(versions []string, reason string) ([]api.ExcludedVersion, error) {
+	var out []api.ExcludedVersion
+	for _, version := range versions {
+		if _, err := semver.NewVersion(version); err != nil {
+			return nil, fmt.Errorf("invalid excluded version %q: %w", version, err)
+		}
+		out = append(out, api.ExcludedVersion{Version: version, Reason: reason})
+	}
+	return out, nil
+}
+
+// convertExcludedVersionListToInternal validates the given excluded versions of a requirement.
+func convertExcludedVersionListToInternal(in []ExcludedVersion) ([]api.ExcludedVersion, error) {
+	var out []api.ExcludedVersion
+	for i := range in {
+		excluded, err := convertExcludedVersionsToInternal([]string{in[i].Version}, emptyStringOrString(in[i].Reason))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, excluded...)
+	}
+	return out, nil
+}
+
+func convertExcludedVersionsToExternal(in []api.ExcludedVersion) []ExcludedVersion {
+	var out []ExcludedVersion
+	for _, excluded := range in {
+		out = append(out, ExcludedVersion{Version: excluded.Version, Reason: nilOrString(excluded.Reason)})
+	}
+	return out
+}
+
+func convertPrereleasePolicyToInternal(in *string) (api.PrereleasePolicy, error) {
+	if in == nil {
+		return "", nil
+	}
+	return api.ParsePrereleasePolicy(*in)
+}
+
 func Convert_v1alpha1_Requirements_To_gem_Requirements(in *Requirements, out *api.Requirements, s conversion.Scope) error {
 	out.Requirements = make(map[api.ModuleKey]*api.Requirement)
 	if err := s.Convert(&in.Requirements, &out.Requirements, 0); err != nil {
 		return err
 	}
 
+	prereleases, err := convertPrereleasePolicyToInternal(in.Prereleases)
+	if err != nil {
+		return fmt.Errorf("error converting %T into %T: %w", in, out, err)
+	}
+	out.Prereleases = prereleases
+
+	out.Includes = nil
+	for _, include := range in.Includes {
+		repository, revision := pointer.StringDerefOr(include.Repository, ""), pointer.StringDerefOr(include.Revision, "")
+		if include.Path == "" {
+			return fmt.Errorf("error converting %T into %T: include without path", in, out)
+		}
+		if (repository == "") != (revision == "") {
+			return fmt.Errorf("error converting %T into %T: include %s has to specify both repository and revision or none of them", in, out, include.Path)
+		}
+		out.Includes = append(out.Includes, api.Include{Repository: repository, Revision: revision, Path: include.Path})
+	}
+
+	out.AllowedOverlaps = convertResourceOverlapsToInternal(in.AllowedOverlaps)
+
+	out.Profiles = nil
+	for i := range in.Profiles {
+		oldProfile := &in.Profiles[i]
+		if _, ok := out.Profiles[oldProfile.Name]; ok {
+			return fmt.Errorf("error converting %T into %T: duplicate profile %s", in, out, oldProfile.Name)
+		}
+
+		newProfile := &api.Profile{Requirements: make(map[api.ModuleKey]*api.Requirement)}
+		if err := s.Convert(&oldProfile.Requirements, &newProfile.Requirements, 0); err != nil {
+			return err
+		}
+		for _, name := range oldProfile.Exclude {
+			moduleKey, err := ExtractModuleKeyFromName(name)
+			if err != nil {
+				return err
+			}
+			newProfile.Exclude = append(newProfile.Exclude, moduleKey)
+		}
+		newProfile.AllowedOverlaps = convertResourceOverlapsToInternal(oldProfile.AllowedOverlaps)
+
+		if out.Profiles == nil {
+			out.Profiles = make(map[string]*api.Profile)
+		}
+		out.Profiles[oldProfile.Name] = newProfile
+	}
 	return nil
 }
 
@@ -121,24 +261,111 @@ func Convert_gem_Requirements_To_v1alpha1_Requirements(in *api.Requirements, out
 		return err
 	}
 
+	out.Prereleases = nilOrString(string(in.Prereleases))
+
+	out.Includes = nil
+	for _, include := range in.Includes {
+		out.Includes = append(out.Includes, Include{
+			Repository: nilOrString(include.Repository),
+			Revision:   nilOrString(include.Revision),
+			Path:       include.Path,
+		})
+	}
+
+	if in.GardenerVersion != "" {
+		return fmt.Errorf("error converting %T into %T: gardener version cannot be represented in %s", in, out, SchemeGroupVersion)
+	}
+	out.AllowedOverlaps = convertResourceOverlapsToExternal(in.AllowedOverlaps)
+
+	out.Profiles = nil
+	for name, newProfile := range in.Profiles {
+		oldProfile := Profile{Name: name}
+		if err := s.Convert(&newProfile.Requirements, &oldProfile.Requirements, 0); err != nil {
+			return err
+		}
+		for i := range newProfile.Exclude {
+			name, err := moduleKeyToName(&newProfile.Exclude[i])
+			if err != nil {
+				return err
+			}
+			oldProfile.Exclude = append(oldProfile.Exclude, name)
+		}
+		sort.Strings(oldProfile.Exclude)
+		if newProfile.GardenerVersion != "" {
+			return fmt.Errorf("error converting %T into %T: gardener version of profile %s cannot be represented in %s", in, out, name, SchemeGroupVersion)
+		}
+		oldProfile.AllowedOverlaps = convertResourceOverlapsToExternal(newProfile.AllowedOverlaps)
+
+		out.Profiles = append(out.Profiles, oldProfile)
+	}
+
+	// Sort by name so the serialized profiles do not depend on the map iteration order.
+	sort.Slice(out.Profiles, func(i, j int) bool { return out.Profiles[i].Name < out.Profiles[j].Name })
 	return nil
 }
 
+func convertResourceOverlapsToInternal(in []ResourceOverlap) []api.ResourceOverlap {
+	var out []api.ResourceOverlap
+	for _, overlap := range in {
+		out = append(out, api.ResourceOverlap{Kind: overlap.Kind, Type: overlap.Type})
+	}
+	return out
+}
+
+func convertResourceOverlapsToExternal(in []api.ResourceOverlap) []ResourceOverlap {
+	var out []ResourceOverlap
+	for _, overlap := range in {
+		out = append(out, ResourceOverlap{Kind: overlap.Kind, Type: overlap.Type})
+	}
+	return out
+}
+
 func Convert_v1alpha1_Requirement_To_gem_Requirement(in *Requirement, out *api.Requirement, s conversion.Scope) error {
 	newTarget := api.NewTarget()
 	if err := s.Convert(&in.Target, newTarget, 0); err != nil {
 		return err
 	}
 
+	submoduleTagPrefix := pointer.BoolDerefOr(in.SubmoduleTagPrefix, false)
+	if submoduleTagPrefix && newTarget.TagPrefix != "" {
+		return fmt.Errorf("error converting %T into %T: tagPrefix and submoduleTagPrefix are mutually exclusive", in, out)
+	}
+
+	var updateStrategy api.UpdateStrategy
+	if in.UpdateStrategy != nil {
+		var err error
+		if updateStrategy, err = api.ParseUpdateStrategy(*in.UpdateStrategy); err != nil {
+			return fmt.Errorf("error converting %T into %T: %w", in, out, err)
+		}
+	}
+
+	if len(in.Exclude) > 0 && newTarget.Type != api.Version {
+		return fmt.Errorf("error converting %T into %T: excluded versions are only allowed for versions", in, out)
+	}
+	exclude, err := convertExcludedVersionListToInternal(in.Exclude)
+	if err != nil {
+		return fmt.Errorf("error converting %T into %T: %w", in, out, err)
+	}
+	newTarget.Exclude = exclude
+
 	*out = api.Requirement{
-		Target:   *newTarget,
-		Filename: pointer.StringDerefOr(in.Filename, DefaultRequirementFilename),
+		Target:             *newTarget,
+		Filename:           pointer.StringDerefOr(in.Filename, DefaultRequirementFilename),
+		SubmoduleTagPrefix: submoduleTagPrefix,
+		UpdateStrategy:     updateStrategy,
 	}
 
 	return nil
 }
 
 func Convert_gem_Requirement_To_v1alpha1_Requirement(in *api.Requirement, out *Requirement, s conversion.Scope) error {
+	if in.URL != "" {
+		return fmt.Errorf("error converting %T into %T: url %s cannot be represented in %s", in, out, in.URL, SchemeGroupVersion)
+	}
+	if len(in.Requires) > 0 || in.Gardener != "" {
+		return fmt.Errorf("error converting %T into %T: constraints cannot be represented in %s", in, out, SchemeGroupVersion)
+	}
+
 	oldTarget := &Target{}
 	if err := s.Convert(&in.Target, oldTarget, 0); err != nil {
 		return err
@@ -149,9 +376,17 @@ func Convert_gem_Requirement_To_v1alpha1_Requirement(in *api.Requirement, out *R
 		filename = &in.Filename
 	}
 
+	var submoduleTagPrefix *bool
+	if in.SubmoduleTagPrefix {
+		submoduleTagPrefix = &in.SubmoduleTagPrefix
+	}
+
 	*out = Requirement{
-		Target:   *oldTarget,
-		Filename: filename,
+		Target:             *oldTarget,
+		Filename:           filename,
+		SubmoduleTagPrefix: submoduleTagPrefix,
+		UpdateStrategy:     nilOrString(string(in.UpdateStrategy)),
+		Exclude:            convertExcludedVersionsToExternal(in.Target.Exclude),
 	}
 
 	return nil
@@ -186,10 +421,17 @@ func Convert_gem_ModuleKeyToRequirement_To_v1alpha1_NamedRequirements(in *map[ap
 			return err
 		}
 
-		namedRequirement := NamedRequirement{Name: ModuleKeyToName(&moduleKey), Requirement: *oldRequirement}
+		name, err := moduleKeyToName(&moduleKey)
+		if err != nil {
+			return err
+		}
+
+		namedRequirement := NamedRequirement{Name: name, Requirement: *oldRequirement}
 		*out = append(*out, namedRequirement)
 	}
 
+	// Sort by name so the serialized requirements do not depend on the map iteration order.
+	sort.Slice(*out, func(i, j int) bool { return (*out)[i].Name < (*out)[j].Name })
 	return nil
 }
 
@@ -222,10 +464,17 @@ func Convert_gem_ModuleKeyToLock_To_v1alpha1_NamedTargetLocks(in *map[api.Module
 			return err
 		}
 
-		namedLock := NamedLock{Name: ModuleKeyToName(&moduleKey), Lock: *oldLock}
+		name, err := moduleKeyToName(&moduleKey)
+		if err != nil {
+			return err
+		}
+
+		namedLock := NamedLock{Name: name, Lock: *oldLock}
 		*out = append(*out, namedLock)
 	}
 
+	// Sort by name so the serialized locks do not depend on the map iteration order.
+	sort.Slice(*out, func(i, j int) bool { return (*out)[i].Name < (*out)[j].Name })
 	return nil
 }
 
@@ -235,6 +484,10 @@ func Convert_v1alpha1_Locks_To_gem_Locks(in *Locks, out *api.Locks, s conversion
 		return err
 	}
 
+	out.Header = api.LocksHeader{}
+	if in.Header != nil {
+		out.Header = api.LocksHeader{RequirementsDigest: in.Header.RequirementsDigest, GemVersion: in.Header.GemVersion}
+	}
 	return nil
 }
 
@@ -244,6 +497,10 @@ func Convert_gem_Locks_To_v1alpha1_Locks(in *api.Locks, out *Locks, s conversion
 		return err
 	}
 
+	out.Header = nil
+	if in.Header != (api.LocksHeader{}) {
+		out.Header = &LocksHeader{RequirementsDigest: in.Header.RequirementsDigest, GemVersion: in.Header.GemVersion}
+	}
 	return nil
 }
 
@@ -255,6 +512,13 @@ func Convert_v1alpha1_Lock_To_gem_Lock(in *Lock, out *api.Lock, s conversion.Sco
 		return err
 	}
 	out.Hash = in.Hash
+	out.TagHash = in.TagHash
+	out.Digest = in.Digest
+	out.Signer = in.Signer
+	out.CommitTime = timeOrZero(in.CommitTime)
+	out.Subject = in.Subject
+	out.ResolvedAt = timeOrZero(in.ResolvedAt)
+	out.GemVersion = in.GemVersion
 	return nil
 }
 
@@ -266,6 +530,100 @@ func Convert_gem_Lock_To_v1alpha1_Lock(in *api.Lock, out *Lock, s conversion.Sco
 		return err
 	}
 	out.Hash = in.Hash
+	out.TagHash = in.TagHash
+	out.Digest = in.Digest
+	out.Signer = in.Signer
+	out.CommitTime = nilOrTime(in.CommitTime)
+	out.Subject = in.Subject
+	out.ResolvedAt = nilOrTime(in.ResolvedAt)
+	out.GemVersion = in.GemVersion
+	return nil
+}
+
+func Convert_v1alpha1_HostCredential_To_gem_HostCredential(in *HostCredential, out *api.HostCredential, s conversion.Scope) error {
+	*out = api.HostCredential{Host: in.Host}
+	ct := 0
+	if in.SSHAgent != nil {
+		ct++
+		out.SSHAgent = &api.SSHAgentCredential{User: pointer.StringDerefOr(in.SSHAgent.User, DefaultSSHUser)}
+	}
+	if in.SSHKey != nil {
+		ct++
+		out.SSHKey = &api.SSHKeyCredential{
+			User:          pointer.StringDerefOr(in.SSHKey.User, DefaultSSHUser),
+			Path:          in.SSHKey.Path,
+			PassphraseEnv: emptyStringOrString(in.SSHKey.PassphraseEnv),
+		}
+	}
+	if in.Basic != nil {
+		ct++
+		out.Basic = &api.BasicCredential{UsernameEnv: in.Basic.UsernameEnv, PasswordEnv: in.Basic.PasswordEnv}
+	}
+	if in.Token != nil {
+		ct++
+		out.Token = &api.TokenCredential{TokenEnv: in.Token.TokenEnv}
+	}
+	if in.Netrc != nil {
+		ct++
+		out.Netrc = &api.NetrcCredential{Path: emptyStringOrString(in.Netrc.Path)}
+	}
+	if in.Helper != nil {
+		ct++
+		out.Helper = &api.CredentialHelper{Helper: emptyStringOrString(in.Helper.Helper)}
+	}
+
+	if ct != 1 {
+		return fmt.Errorf("error converting %T into %T: exactly one credential source has to be specified for host %q", in, out, in.Host)
+	}
+	return nil
+}
+
+func Convert_gem_HostCredential_To_v1alpha1_HostCredential(in *api.HostCredential, out *HostCredential, s conversion.Scope) error {
+	*out = HostCredential{Host: in.Host}
+	if in.SSHAgent != nil {
+		out.SSHAgent = &SSHAgentCredential{User: nilOrString(in.SSHAgent.User)}
+	}
+	if in.SSHKey != nil {
+		out.SSHKey = &SSHKeyCredential{
+			User:          nilOrString(in.SSHKey.User),
+			Path:          in.SSHKey.Path,
+			PassphraseEnv: nilOrString(in.SSHKey.PassphraseEnv),
+		}
+	}
+	if in.Basic != nil {
+		out.Basic = &BasicCredential{UsernameEnv: in.Basic.UsernameEnv, PasswordEnv: in.Basic.PasswordEnv}
+	}
+	if in.Token != nil {
+		out.Token = &TokenCredential{TokenEnv: in.Token.TokenEnv}
+	}
+	if in.Netrc != nil {
+		out.Netrc = &NetrcCredential{Path: nilOrString(in.Netrc.Path)}
+	}
+	if in.Helper != nil {
+		out.Helper = &CredentialHelper{Helper: nilOrString(in.Helper.Helper)}
+	}
+	return nil
+}
+
+func Convert_v1alpha1_Credentials_To_gem_Credentials(in *Credentials, out *api.Credentials, s conversion.Scope) error {
+	out.Credentials = make([]api.HostCredential, len(in.Credentials))
+	for i := range in.Credentials {
+		if err := s.Convert(&in.Credentials[i], &out.Credentials[i], 0); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func Convert_gem_Credentials_To_v1alpha1_Credentials(in *api.Credentials, out *Credentials, s conversion.Scope) error {
+	out.Credentials = make([]HostCredential, len(in.Credentials))
+	for i := range in.Credentials {
+		if err := s.Convert(&in.Credentials[i], &out.Credentials[i], 0); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -357,5 +715,30 @@ func addConversionFuncs(scheme *runtime.Scheme) error {
 		return err
 	}
 
+	// credentials
+	if err := scheme.AddConversionFunc((*HostCredential)(nil), (*api.HostCredential)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_HostCredential_To_gem_HostCredential(a.(*HostCredential), b.(*api.HostCredential), scope)
+	}); err != nil {
+		return err
+	}
+
+	if err := scheme.AddConversionFunc((*api.HostCredential)(nil), (*HostCredential)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_gem_HostCredential_To_v1alpha1_HostCredential(a.(*api.HostCredential), b.(*HostCredential), scope)
+	}); err != nil {
+		return err
+	}
+
+	if err := scheme.AddConversionFunc((*Credentials)(nil), (*api.Credentials)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_Credentials_To_gem_Credentials(a.(*Credentials), b.(*api.Credentials), scope)
+	}); err != nil {
+		return err
+	}
+
+	if err := scheme.AddConversionFunc((*api.Credentials)(nil), (*Credentials)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_gem_Credentials_To_v1alpha1_Credentials(a.(*api.Credentials), b.(*Credentials), scope)
+	}); err != nil {
+		return err
+	}
+
 	return nil
 }
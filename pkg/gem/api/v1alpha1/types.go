@@ -14,17 +14,68 @@
 
 package v1alpha1
 
-import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+import (
+	"encoding/json"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
 
 type Target struct {
-	Version  *string `json:"version,omitempty"`
-	Revision *string `json:"revision,omitempty"`
-	Branch   *string `json:"branch,omitempty"`
+	Version   *string `json:"version,omitempty"`
+	Revision  *string `json:"revision,omitempty"`
+	Branch    *string `json:"branch,omitempty"`
+	TagPrefix *string `json:"tagPrefix,omitempty"`
+	// Prereleases controls whether a version may resolve to a prerelease, one of `exclude`, `include` or
+	// `only-if-newer`. By default, prereleases only match constraints that contain a prerelease themselves.
+	Prereleases *string `json:"prereleases,omitempty"`
 }
 
 type Requirement struct {
-	Target   `json:",inline,omitempty"`
-	Filename *string `json:"filename,omitempty"`
+	Target             `json:",inline,omitempty"`
+	Filename           *string `json:"filename,omitempty"`
+	SubmoduleTagPrefix *bool   `json:"submoduleTagPrefix,omitempty"`
+	// UpdateStrategy limits how far an update may move the lock relative to the locked version,
+	// one of `patch`, `minor`, `major` or `latest-in-range` (default).
+	UpdateStrategy *string `json:"updateStrategy,omitempty"`
+	// Exclude are versions that must never be resolved although they satisfy the version, e.g. `1.4.2` or
+	// `{version: 1.4.2, reason: ...}`.
+	Exclude []ExcludedVersion `json:"exclude,omitempty"`
+}
+
+// ExcludedVersion is a version that must never be resolved. Without a reason, it is written as plain version,
+// e.g. `1.4.2`, otherwise as object with `version` and `reason`.
+type ExcludedVersion struct {
+	// Version is the excluded version without tag prefix, e.g. `1.4.2`.
+	Version string `json:"version"`
+	// Reason explains why the version is excluded, e.g. a link to an advisory.
+	Reason *string `json:"reason,omitempty"`
+}
+
+// UnmarshalJSON accepts either a plain version or an object with version and reason.
+func (e *ExcludedVersion) UnmarshalJSON(data []byte) error {
+	var version string
+	if err := json.Unmarshal(data, &version); err == nil {
+		*e = ExcludedVersion{Version: version}
+		return nil
+	}
+
+	type excludedVersion ExcludedVersion
+	out := excludedVersion{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	*e = ExcludedVersion(out)
+	return nil
+}
+
+// MarshalJSON writes versions without a reason as plain versions.
+func (e ExcludedVersion) MarshalJSON() ([]byte, error) {
+	if e.Reason == nil {
+		return json.Marshal(e.Version)
+	}
+
+	type excludedVersion ExcludedVersion
+	return json.Marshal(excludedVersion(e))
 }
 
 type NamedRequirement struct {
@@ -39,12 +90,53 @@ type Requirements struct {
 	metav1.TypeMeta `json:",inline"`
 
 	Requirements []NamedRequirement `json:"requirements,omitempty"`
+	// Prereleases is the prerelease policy of all version requirements of this file that do not specify one.
+	Prereleases *string `json:"prereleases,omitempty"`
+	// Includes are other requirements files that are merged into these requirements, in order. Requirements of
+	// later includes replace the ones of earlier includes, the own requirements replace all included ones.
+	Includes []Include `json:"includes,omitempty"`
+	// AllowedOverlaps are the resources that may be registered as primary by more than one extension.
+	AllowedOverlaps []ResourceOverlap `json:"allowedOverlaps,omitempty"`
+	// Profiles are named overlays of the requirements, e.g. for different landscapes.
+	Profiles []Profile `json:"profiles,omitempty"`
+}
+
+// Include refers to a requirements file, either relative to the including file or in a repository
+// at a pinned revision.
+type Include struct {
+	Repository *string `json:"repository,omitempty"`
+	Revision   *string `json:"revision,omitempty"`
+	Path       string  `json:"path"`
+}
+
+// Profile overlays the requirements it is part of.
+type Profile struct {
+	Name string `json:"name"`
+	// Requirements are added to the base requirements, replacing the base requirements of the same name.
+	Requirements []NamedRequirement `json:"requirements,omitempty"`
+	// Exclude are the names of the base requirements to remove.
+	Exclude []string `json:"exclude,omitempty"`
+	// AllowedOverlaps are allowed in addition to the allowed overlaps of the base requirements.
+	AllowedOverlaps []ResourceOverlap `json:"allowedOverlaps,omitempty"`
+}
+
+// ResourceOverlap is a kind/type combination of an extension resource, e.g. `Infrastructure/aws`.
+type ResourceOverlap struct {
+	Kind string `json:"kind"`
+	Type string `json:"type"`
 }
 
 type Lock struct {
-	Hash     string `json:"hash"`
-	Target   `json:",inline"`
-	Resolved Target `json:"resolved"`
+	Hash       string       `json:"hash"`
+	TagHash    string       `json:"tagHash,omitempty"`
+	Digest     string       `json:"digest,omitempty"`
+	Signer     string       `json:"signer,omitempty"`
+	CommitTime *metav1.Time `json:"commitTime,omitempty"`
+	Subject    string       `json:"subject,omitempty"`
+	ResolvedAt *metav1.Time `json:"resolvedAt,omitempty"`
+	GemVersion string       `json:"gemVersion,omitempty"`
+	Target     `json:",inline"`
+	Resolved   Target `json:"resolved"`
 }
 
 type NamedLock struct {
@@ -58,5 +150,64 @@ type NamedLock struct {
 type Locks struct {
 	metav1.TypeMeta `json:",inline"`
 
-	Locks []NamedLock `json:"locks,omitempty"`
+	Header *LocksHeader `json:"header,omitempty"`
+	Locks  []NamedLock  `json:"locks,omitempty"`
+}
+
+// LocksHeader describes how the locks were resolved.
+type LocksHeader struct {
+	// RequirementsDigest is the digest of the requirements the locks were resolved from, e.g. `sha256:<hex>`.
+	RequirementsDigest string `json:"requirementsDigest,omitempty"`
+	// GemVersion is the version of gem that resolved the locks.
+	GemVersion string `json:"gemVersion,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// Credentials configures how to authenticate against the hosts of repositories.
+type Credentials struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Credentials are checked in order, the first one whose host pattern matches is used.
+	Credentials []HostCredential `json:"credentials,omitempty"`
+}
+
+// HostCredential is a credential for all repositories whose host (and optionally path) match the host pattern.
+// Exactly one of the credential sources has to be set.
+type HostCredential struct {
+	Host string `json:"host"`
+
+	SSHAgent *SSHAgentCredential `json:"sshAgent,omitempty"`
+	SSHKey   *SSHKeyCredential   `json:"sshKey,omitempty"`
+	Basic    *BasicCredential    `json:"basic,omitempty"`
+	Token    *TokenCredential    `json:"token,omitempty"`
+	Netrc    *NetrcCredential    `json:"netrc,omitempty"`
+	Helper   *CredentialHelper   `json:"helper,omitempty"`
+}
+
+type SSHAgentCredential struct {
+	User *string `json:"user,omitempty"`
+}
+
+type SSHKeyCredential struct {
+	User          *string `json:"user,omitempty"`
+	Path          string  `json:"path"`
+	PassphraseEnv *string `json:"passphraseEnv,omitempty"`
+}
+
+type BasicCredential struct {
+	UsernameEnv string `json:"usernameEnv"`
+	PasswordEnv string `json:"passwordEnv"`
+}
+
+type TokenCredential struct {
+	TokenEnv string `json:"tokenEnv"`
+}
+
+type NetrcCredential struct {
+	Path *string `json:"path,omitempty"`
+}
+
+type CredentialHelper struct {
+	Helper *string `json:"helper,omitempty"`
 }
@@ -22,9 +22,173 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BasicCredential) DeepCopyInto(out *BasicCredential) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BasicCredential.
+func (in *BasicCredential) DeepCopy() *BasicCredential {
+	if in == nil {
+		return nil
+	}
+	out := new(BasicCredential)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CredentialHelper) DeepCopyInto(out *CredentialHelper) {
+	*out = *in
+	if in.Helper != nil {
+		in, out := &in.Helper, &out.Helper
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CredentialHelper.
+func (in *CredentialHelper) DeepCopy() *CredentialHelper {
+	if in == nil {
+		return nil
+	}
+	out := new(CredentialHelper)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Credentials) DeepCopyInto(out *Credentials) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.Credentials != nil {
+		in, out := &in.Credentials, &out.Credentials
+		*out = make([]HostCredential, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Credentials.
+func (in *Credentials) DeepCopy() *Credentials {
+	if in == nil {
+		return nil
+	}
+	out := new(Credentials)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Credentials) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExcludedVersion) DeepCopyInto(out *ExcludedVersion) {
+	*out = *in
+	if in.Reason != nil {
+		in, out := &in.Reason, &out.Reason
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExcludedVersion.
+func (in *ExcludedVersion) DeepCopy() *ExcludedVersion {
+	if in == nil {
+		return nil
+	}
+	out := new(ExcludedVersion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostCredential) DeepCopyInto(out *HostCredential) {
+	*out = *in
+	if in.SSHAgent != nil {
+		in, out := &in.SSHAgent, &out.SSHAgent
+		*out = new(SSHAgentCredential)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SSHKey != nil {
+		in, out := &in.SSHKey, &out.SSHKey
+		*out = new(SSHKeyCredential)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Basic != nil {
+		in, out := &in.Basic, &out.Basic
+		*out = new(BasicCredential)
+		**out = **in
+	}
+	if in.Token != nil {
+		in, out := &in.Token, &out.Token
+		*out = new(TokenCredential)
+		**out = **in
+	}
+	if in.Netrc != nil {
+		in, out := &in.Netrc, &out.Netrc
+		*out = new(NetrcCredential)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Helper != nil {
+		in, out := &in.Helper, &out.Helper
+		*out = new(CredentialHelper)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostCredential.
+func (in *HostCredential) DeepCopy() *HostCredential {
+	if in == nil {
+		return nil
+	}
+	out := new(HostCredential)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Include) DeepCopyInto(out *Include) {
+	*out = *in
+	if in.Repository != nil {
+		in, out := &in.Repository, &out.Repository
+		*out = new(string)
+		**out = **in
+	}
+	if in.Revision != nil {
+		in, out := &in.Revision, &out.Revision
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Include.
+func (in *Include) DeepCopy() *Include {
+	if in == nil {
+		return nil
+	}
+	out := new(Include)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Lock) DeepCopyInto(out *Lock) {
 	*out = *in
+	if in.CommitTime != nil {
+		in, out := &in.CommitTime, &out.CommitTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ResolvedAt != nil {
+		in, out := &in.ResolvedAt, &out.ResolvedAt
+		*out = (*in).DeepCopy()
+	}
 	in.Target.DeepCopyInto(&out.Target)
 	in.Resolved.DeepCopyInto(&out.Resolved)
 }
@@ -43,6 +207,11 @@ func (in *Lock) DeepCopy() *Lock {
 func (in *Locks) DeepCopyInto(out *Locks) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
+	if in.Header != nil {
+		in, out := &in.Header, &out.Header
+		*out = new(LocksHeader)
+		**out = **in
+	}
 	if in.Locks != nil {
 		in, out := &in.Locks, &out.Locks
 		*out = make([]NamedLock, len(*in))
@@ -70,6 +239,21 @@ func (in *Locks) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocksHeader) DeepCopyInto(out *LocksHeader) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocksHeader.
+func (in *LocksHeader) DeepCopy() *LocksHeader {
+	if in == nil {
+		return nil
+	}
+	out := new(LocksHeader)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NamedLock) DeepCopyInto(out *NamedLock) {
 	*out = *in
@@ -102,6 +286,58 @@ func (in *NamedRequirement) DeepCopy() *NamedRequirement {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetrcCredential) DeepCopyInto(out *NetrcCredential) {
+	*out = *in
+	if in.Path != nil {
+		in, out := &in.Path, &out.Path
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetrcCredential.
+func (in *NetrcCredential) DeepCopy() *NetrcCredential {
+	if in == nil {
+		return nil
+	}
+	out := new(NetrcCredential)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Profile) DeepCopyInto(out *Profile) {
+	*out = *in
+	if in.Requirements != nil {
+		in, out := &in.Requirements, &out.Requirements
+		*out = make([]NamedRequirement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedOverlaps != nil {
+		in, out := &in.AllowedOverlaps, &out.AllowedOverlaps
+		*out = make([]ResourceOverlap, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Profile.
+func (in *Profile) DeepCopy() *Profile {
+	if in == nil {
+		return nil
+	}
+	out := new(Profile)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Requirement) DeepCopyInto(out *Requirement) {
 	*out = *in
@@ -111,6 +347,23 @@ func (in *Requirement) DeepCopyInto(out *Requirement) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.SubmoduleTagPrefix != nil {
+		in, out := &in.SubmoduleTagPrefix, &out.SubmoduleTagPrefix
+		*out = new(bool)
+		**out = **in
+	}
+	if in.UpdateStrategy != nil {
+		in, out := &in.UpdateStrategy, &out.UpdateStrategy
+		*out = new(string)
+		**out = **in
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]ExcludedVersion, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Requirement.
@@ -134,6 +387,30 @@ func (in *Requirements) DeepCopyInto(out *Requirements) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Prereleases != nil {
+		in, out := &in.Prereleases, &out.Prereleases
+		*out = new(string)
+		**out = **in
+	}
+	if in.Includes != nil {
+		in, out := &in.Includes, &out.Includes
+		*out = make([]Include, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AllowedOverlaps != nil {
+		in, out := &in.AllowedOverlaps, &out.AllowedOverlaps
+		*out = make([]ResourceOverlap, len(*in))
+		copy(*out, *in)
+	}
+	if in.Profiles != nil {
+		in, out := &in.Profiles, &out.Profiles
+		*out = make([]Profile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Requirements.
@@ -154,6 +431,66 @@ func (in *Requirements) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceOverlap) DeepCopyInto(out *ResourceOverlap) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceOverlap.
+func (in *ResourceOverlap) DeepCopy() *ResourceOverlap {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceOverlap)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SSHAgentCredential) DeepCopyInto(out *SSHAgentCredential) {
+	*out = *in
+	if in.User != nil {
+		in, out := &in.User, &out.User
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SSHAgentCredential.
+func (in *SSHAgentCredential) DeepCopy() *SSHAgentCredential {
+	if in == nil {
+		return nil
+	}
+	out := new(SSHAgentCredential)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SSHKeyCredential) DeepCopyInto(out *SSHKeyCredential) {
+	*out = *in
+	if in.User != nil {
+		in, out := &in.User, &out.User
+		*out = new(string)
+		**out = **in
+	}
+	if in.PassphraseEnv != nil {
+		in, out := &in.PassphraseEnv, &out.PassphraseEnv
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SSHKeyCredential.
+func (in *SSHKeyCredential) DeepCopy() *SSHKeyCredential {
+	if in == nil {
+		return nil
+	}
+	out := new(SSHKeyCredential)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Target) DeepCopyInto(out *Target) {
 	*out = *in
@@ -172,6 +509,16 @@ func (in *Target) DeepCopyInto(out *Target) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.TagPrefix != nil {
+		in, out := &in.TagPrefix, &out.TagPrefix
+		*out = new(string)
+		**out = **in
+	}
+	if in.Prereleases != nil {
+		in, out := &in.Prereleases, &out.Prereleases
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Target.
@@ -183,3 +530,18 @@ func (in *Target) DeepCopy() *Target {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TokenCredential) DeepCopyInto(out *TokenCredential) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TokenCredential.
+func (in *TokenCredential) DeepCopy() *TokenCredential {
+	if in == nil {
+		return nil
+	}
+	out := new(TokenCredential)
+	in.DeepCopyInto(out)
+	return out
+}
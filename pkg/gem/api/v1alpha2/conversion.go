@@ -0,0 +1,961 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha2
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"github.com/gardener/gem/pkg/util/pointer"
+
+	"github.com/gardener/gem/pkg/gem/api"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/conversion"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	DefaultRequirementFilename = "controller-registration.yaml"
+	DefaultSSHUser             = "git"
+
+	// sourceSubmoduleSeparator separates the repository from the submodule in a source.
+	sourceSubmoduleSeparator = "//"
+	schemeSeparator          = "://"
+)
+
+func emptyStringOrString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func timeOrZero(t *metav1.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return t.Time
+}
+
+func nilOrTime(t time.Time) *metav1.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &metav1.Time{Time: t}
+}
+
+func nilOrString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// ParseSource splits a source of the form `<repository>[//<submodule>]` into a ModuleKey. A trailing `//` marks
+// the end of the repository without a submodule. The `//` of the scheme of the repository, e.g. `file:///tmp/repo`,
+// is not treated as separator.
+func ParseSource(source string) (api.ModuleKey, error) {
+	offset := 0
+	if idx := strings.Index(source, schemeSeparator); idx >= 0 {
+		offset = idx + len(schemeSeparator)
+	}
+
+	repository, submodule := source, ""
+	if idx := strings.Index(source[offset:], sourceSubmoduleSeparator); idx >= 0 {
+		repository, submodule = source[:offset+idx], strings.Trim(source[offset+idx+len(sourceSubmoduleSeparator):], "/")
+	}
+
+	if len(repository) <= offset {
+		return api.ModuleKey{}, fmt.Errorf("source %s has an empty repository", source)
+	}
+	return api.ModuleKey{Repository: repository, Submodule: submodule}, nil
+}
+
+// IsSource returns whether the given name is a source, i.e. whether it separates repository and submodule via `//`.
+func IsSource(name string) bool {
+	if idx := strings.Index(name, schemeSeparator); idx >= 0 {
+		name = name[idx+len(schemeSeparator):]
+	}
+	return strings.Contains(name, sourceSubmoduleSeparator)
+}
+
+// ModuleKeyToSource converts the given ModuleKey into a source of the form `<repository>[//<submodule>]`.
+func ModuleKeyToSource(key *api.ModuleKey) string {
+	if key.Submodule == "" {
+		return key.Repository
+	}
+	return key.Repository + sourceSubmoduleSeparator + key.Submodule
+}
+
+// NormalizeTagPrefix makes sure a non-empty tag prefix ends with exactly one slash.
+func NormalizeTagPrefix(prefix string) string {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return ""
+	}
+	return prefix + "/"
+}
+
+func convertModuleReferenceToInternal(in *ModuleReference) (api.ModuleKey, error) {
+	if in.Source != nil {
+		if in.Repository != nil || in.Submodule != nil {
+			return api.ModuleKey{}, fmt.Errorf("source %s is mutually exclusive with repository and submodule", *in.Source)
+		}
+		return ParseSource(*in.Source)
+	}
+
+	repository := emptyStringOrString(in.Repository)
+	if repository == "" {
+		return api.ModuleKey{}, fmt.Errorf("either source or repository has to be specified")
+	}
+	return api.ModuleKey{Repository: repository, Submodule: strings.Trim(emptyStringOrString(in.Submodule), "/")}, nil
+}
+
+func convertModuleKeyToExternal(in *api.ModuleKey) ModuleReference {
+	return ModuleReference{
+		Repository: nilOrString(in.Repository),
+		Submodule:  nilOrString(in.Submodule),
+	}
+}
+
+func Convert_v1alpha2_Target_To_gem_Target(in *Target, out *api.Target, s conversion.Scope) error {
+	ct := 0
+	var (
+		targetType api.TargetType
+		version    string
+		revision   string
+		branch     string
+	)
+	if in.Revision != nil {
+		ct++
+		revision = *in.Revision
+		targetType = api.Revision
+	}
+	if in.Version != nil {
+		ct++
+		version = *in.Version
+		targetType = api.Version
+	}
+	if in.Branch != nil {
+		ct++
+		branch = *in.Branch
+		targetType = api.Branch
+	}
+	if ct == 0 {
+		targetType = api.Latest
+	}
+
+	if ct > 1 {
+		return fmt.Errorf("error converting %T into %T: more than one target definition is not allowed", in, out)
+	}
+	if in.TagPrefix != nil && targetType != api.Version {
+		return fmt.Errorf("error converting %T into %T: a tag prefix is only allowed for versions", in, out)
+	}
+	if in.Prereleases != nil && targetType != api.Version {
+		return fmt.Errorf("error converting %T into %T: a prerelease policy is only allowed for versions", in, out)
+	}
+	prereleases, err := convertPrereleasePolicyToInternal(in.Prereleases)
+	if err != nil {
+		return fmt.Errorf("error converting %T into %T: %w", in, out, err)
+	}
+	*out = api.Target{
+		Type:        targetType,
+		Version:     version,
+		Revision:    revision,
+		Branch:      branch,
+		TagPrefix:   NormalizeTagPrefix(emptyStringOrString(in.TagPrefix)),
+		Prereleases: prereleases,
+	}
+	return nil
+}
+
+func Convert_gem_Target_To_v1alpha2_Target(in *api.Target, out *Target, s conversion.Scope) error {
+	*out = Target{
+		Version:     nilOrString(in.Version),
+		Revision:    nilOrString(in.Revision),
+		Branch:      nilOrString(in.Branch),
+		TagPrefix:   nilOrString(in.TagPrefix),
+		Prereleases: nilOrString(string(in.Prereleases)),
+	}
+	return nil
+}
+
+// convertExcludedVersionsToInternal validates the given versions and excludes them for the given reason.
+func convertExcludedVersionsToInternal(versions []string, reason string) ([]api.ExcludedVersion, error) {
+	var out []api.ExcludedVersion
+	for _, version := range versions {
+		if _, err := semver.NewVersion(version); err != nil {
+			return nil, fmt.Errorf("invalid excluded version %q: %w", version, err)
+		}
+		out = append(out, api.ExcludedVersion{Version: version, Reason: reason})
+	}
+	return out, nil
+}
+
+// convertExcludedVersionListToInternal validates the given excluded versions of a requirement.
+func convertExcludedVersionListToInternal(in []ExcludedVersion) ([]api.ExcludedVersion, error) {
+	var out []api.ExcludedVersion
+	for i := range in {
+		excluded, err := convertExcludedVersionsToInternal([]string{in[i].Version}, emptyStringOrString(in[i].Reason))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, excluded...)
+	}
+	return out, nil
+}
+
+func convertExcludedVersionsToExternal(in []api.ExcludedVersion) []ExcludedVersion {
+	var out []ExcludedVersion
+	for _, excluded := range in {
+		out = append(out, ExcludedVersion{Version: excluded.Version, Reason: nilOrString(excluded.Reason)})
+	}
+	return out
+}
+
+// convertModuleConstraintsToInternal validates the given module constraints and converts them by module.
+func convertModuleConstraintsToInternal(in []ModuleConstraint) (map[api.ModuleKey]string, error) {
+	var out map[api.ModuleKey]string
+	for i := range in {
+		moduleKey, err := convertModuleReferenceToInternal(&in[i].ModuleReference)
+		if err != nil {
+			return nil, fmt.Errorf("invalid required module: %w", err)
+		}
+		if _, ok := out[moduleKey]; ok {
+			return nil, fmt.Errorf("duplicate required module %s", &moduleKey)
+		}
+		if _, err := semver.NewConstraint(in[i].Version); err != nil {
+			return nil, fmt.Errorf("invalid version range %q of required module %s: %w", in[i].Version, &moduleKey, err)
+		}
+
+		if out == nil {
+			out = make(map[api.ModuleKey]string)
+		}
+		out[moduleKey] = in[i].Version
+	}
+	return out, nil
+}
+
+func convertModuleConstraintsToExternal(in map[api.ModuleKey]string) []ModuleConstraint {
+	var out []ModuleConstraint
+	for moduleKey, version := range in {
+		moduleKey := moduleKey
+		out = append(out, ModuleConstraint{ModuleReference: convertModuleKeyToExternal(&moduleKey), Version: version})
+	}
+
+	// Sort by module so the serialized constraints do not depend on the map iteration order.
+	sort.Slice(out, func(i, j int) bool { return moduleReferenceLess(&out[i].ModuleReference, &out[j].ModuleReference) })
+	return out
+}
+
+func convertVersionRangeToInternal(in *string) (string, error) {
+	if in == nil {
+		return "", nil
+	}
+	if _, err := semver.NewConstraint(*in); err != nil {
+		return "", err
+	}
+	return *in, nil
+}
+
+func convertGardenerVersionToInternal(in *string) (string, error) {
+	if in == nil {
+		return "", nil
+	}
+	if _, err := semver.NewVersion(*in); err != nil {
+		return "", fmt.Errorf("invalid gardener version %q: %w", *in, err)
+	}
+	return *in, nil
+}
+
+func convertPrereleasePolicyToInternal(in *string) (api.PrereleasePolicy, error) {
+	if in == nil {
+		return "", nil
+	}
+	return api.ParsePrereleasePolicy(*in)
+}
+
+func Convert_v1alpha2_Requirements_To_gem_Requirements(in *Requirements, out *api.Requirements, s conversion.Scope) error {
+	out.Requirements = make(map[api.ModuleKey]*api.Requirement)
+	if err := s.Convert(&in.Requirements, &out.Requirements, 0); err != nil {
+		return err
+	}
+
+	prereleases, err := convertPrereleasePolicyToInternal(in.Prereleases)
+	if err != nil {
+		return fmt.Errorf("error converting %T into %T: %w", in, out, err)
+	}
+	out.Prereleases = prereleases
+
+	out.Includes = nil
+	for _, include := range in.Includes {
+		repository, revision := pointer.StringDerefOr(include.Repository, ""), pointer.StringDerefOr(include.Revision, "")
+		if include.Path == "" {
+			return fmt.Errorf("error converting %T into %T: include without path", in, out)
+		}
+		if (repository == "") != (revision == "") {
+			return fmt.Errorf("error converting %T into %T: include %s has to specify both repository and revision or none of them", in, out, include.Path)
+		}
+		if revision != "" && !api.IsCommitHash(revision) {
+			return fmt.Errorf("error converting %T into %T: include %s has to pin revision %q to a full 40-character commit hash, tags, branches and abbreviated hashes are not supported", in, out, include.Path, revision)
+		}
+		out.Includes = append(out.Includes, api.Include{Repository: repository, Revision: revision, Path: include.Path})
+	}
+
+	out.AllowedOverlaps = convertResourceOverlapsToInternal(in.AllowedOverlaps)
+
+	if out.GardenerVersion, err = convertGardenerVersionToInternal(in.GardenerVersion); err != nil {
+		return fmt.Errorf("error converting %T into %T: %w", in, out, err)
+	}
+
+	out.Profiles = nil
+	for i := range in.Profiles {
+		oldProfile := &in.Profiles[i]
+		if _, ok := out.Profiles[oldProfile.Name]; ok {
+			return fmt.Errorf("error converting %T into %T: duplicate profile %s", in, out, oldProfile.Name)
+		}
+
+		newProfile := &api.Profile{Requirements: make(map[api.ModuleKey]*api.Requirement)}
+		if err := s.Convert(&oldProfile.Requirements, &newProfile.Requirements, 0); err != nil {
+			return err
+		}
+		for j := range oldProfile.Exclude {
+			moduleKey, err := convertModuleReferenceToInternal(&oldProfile.Exclude[j])
+			if err != nil {
+				return fmt.Errorf("error converting %T into %T: invalid exclude of profile %s: %w", in, out, oldProfile.Name, err)
+			}
+			newProfile.Exclude = append(newProfile.Exclude, moduleKey)
+		}
+		newProfile.AllowedOverlaps = convertResourceOverlapsToInternal(oldProfile.AllowedOverlaps)
+		if newProfile.GardenerVersion, err = convertGardenerVersionToInternal(oldProfile.GardenerVersion); err != nil {
+			return fmt.Errorf("error converting %T into %T: profile %s: %w", in, out, oldProfile.Name, err)
+		}
+
+		if out.Profiles == nil {
+			out.Profiles = make(map[string]*api.Profile)
+		}
+		out.Profiles[oldProfile.Name] = newProfile
+	}
+	return nil
+}
+
+func Convert_gem_Requirements_To_v1alpha2_Requirements(in *api.Requirements, out *Requirements, s conversion.Scope) error {
+	out.Requirements = make([]Requirement, 0, 0)
+	if err := s.Convert(&in.Requirements, &out.Requirements, 0); err != nil {
+		return err
+	}
+
+	out.Prereleases = nilOrString(string(in.Prereleases))
+
+	out.Includes = nil
+	for _, include := range in.Includes {
+		out.Includes = append(out.Includes, Include{
+			Repository: nilOrString(include.Repository),
+			Revision:   nilOrString(include.Revision),
+			Path:       include.Path,
+		})
+	}
+
+	out.AllowedOverlaps = convertResourceOverlapsToExternal(in.AllowedOverlaps)
+	out.GardenerVersion = nilOrString(in.GardenerVersion)
+
+	out.Profiles = nil
+	for name, newProfile := range in.Profiles {
+		oldProfile := Profile{Name: name}
+		if err := s.Convert(&newProfile.Requirements, &oldProfile.Requirements, 0); err != nil {
+			return err
+		}
+
+		exclude := append([]api.ModuleKey(nil), newProfile.Exclude...)
+		sort.Slice(exclude, func(i, j int) bool { return exclude[i].String() < exclude[j].String() })
+		for i := range exclude {
+			oldProfile.Exclude = append(oldProfile.Exclude, convertModuleKeyToExternal(&exclude[i]))
+		}
+		oldProfile.AllowedOverlaps = convertResourceOverlapsToExternal(newProfile.AllowedOverlaps)
+		oldProfile.GardenerVersion = nilOrString(newProfile.GardenerVersion)
+
+		out.Profiles = append(out.Profiles, oldProfile)
+	}
+
+	// Sort by name so the serialized profiles do not depend on the map iteration order.
+	sort.Slice(out.Profiles, func(i, j int) bool { return out.Profiles[i].Name < out.Profiles[j].Name })
+	return nil
+}
+
+func convertResourceOverlapsToInternal(in []ResourceOverlap) []api.ResourceOverlap {
+	var out []api.ResourceOverlap
+	for _, overlap := range in {
+		out = append(out, api.ResourceOverlap{Kind: overlap.Kind, Type: overlap.Type})
+	}
+	return out
+}
+
+func convertResourceOverlapsToExternal(in []api.ResourceOverlap) []ResourceOverlap {
+	var out []ResourceOverlap
+	for _, overlap := range in {
+		out = append(out, ResourceOverlap{Kind: overlap.Kind, Type: overlap.Type})
+	}
+	return out
+}
+
+// Convert_v1alpha2_Requirement_To_gem_Requirement converts everything but the module reference, which is the
+// key the requirement is stored under.
+func Convert_v1alpha2_Requirement_To_gem_Requirement(in *Requirement, out *api.Requirement, s conversion.Scope) error {
+	newTarget := api.NewTarget()
+	if err := s.Convert(&in.Target, newTarget, 0); err != nil {
+		return err
+	}
+
+	submoduleTagPrefix := pointer.BoolDerefOr(in.SubmoduleTagPrefix, false)
+	if submoduleTagPrefix && newTarget.TagPrefix != "" {
+		return fmt.Errorf("error converting %T into %T: tagPrefix and submoduleTagPrefix are mutually exclusive", in, out)
+	}
+
+	var updateStrategy api.UpdateStrategy
+	if in.UpdateStrategy != nil {
+		var err error
+		if updateStrategy, err = api.ParseUpdateStrategy(*in.UpdateStrategy); err != nil {
+			return fmt.Errorf("error converting %T into %T: %w", in, out, err)
+		}
+	}
+
+	if len(in.Exclude) > 0 && newTarget.Type != api.Version {
+		return fmt.Errorf("error converting %T into %T: excluded versions are only allowed for versions", in, out)
+	}
+	exclude, err := convertExcludedVersionListToInternal(in.Exclude)
+	if err != nil {
+		return fmt.Errorf("error converting %T into %T: %w", in, out, err)
+	}
+	newTarget.Exclude = exclude
+
+	requires, err := convertModuleConstraintsToInternal(in.Requires)
+	if err != nil {
+		return fmt.Errorf("error converting %T into %T: %w", in, out, err)
+	}
+	gardener, err := convertVersionRangeToInternal(in.Gardener)
+	if err != nil {
+		return fmt.Errorf("error converting %T into %T: invalid gardener version range: %w", in, out, err)
+	}
+
+	*out = api.Requirement{
+		Target:             *newTarget,
+		Filename:           pointer.StringDerefOr(in.Filename, DefaultRequirementFilename),
+		URL:                emptyStringOrString(in.URL),
+		SubmoduleTagPrefix: submoduleTagPrefix,
+		UpdateStrategy:     updateStrategy,
+		Requires:           requires,
+		Gardener:           gardener,
+	}
+
+	return nil
+}
+
+// Convert_gem_Requirement_To_v1alpha2_Requirement converts everything but the module reference, which is the
+// key the requirement is stored under.
+func Convert_gem_Requirement_To_v1alpha2_Requirement(in *api.Requirement, out *Requirement, s conversion.Scope) error {
+	oldTarget := &Target{}
+	if err := s.Convert(&in.Target, oldTarget, 0); err != nil {
+		return err
+	}
+
+	var filename *string
+	if in.Filename != DefaultRequirementFilename {
+		filename = &in.Filename
+	}
+
+	var submoduleTagPrefix *bool
+	if in.SubmoduleTagPrefix {
+		submoduleTagPrefix = &in.SubmoduleTagPrefix
+	}
+
+	*out = Requirement{
+		URL:                nilOrString(in.URL),
+		Target:             *oldTarget,
+		Filename:           filename,
+		SubmoduleTagPrefix: submoduleTagPrefix,
+		UpdateStrategy:     nilOrString(string(in.UpdateStrategy)),
+		Exclude:            convertExcludedVersionsToExternal(in.Target.Exclude),
+		Requires:           convertModuleConstraintsToExternal(in.Requires),
+		Gardener:           nilOrString(in.Gardener),
+	}
+
+	return nil
+}
+
+func Convert_v1alpha2_Requirements_To_gem_ModuleKeyToRequirement(in *[]Requirement, out *map[api.ModuleKey]*api.Requirement, s conversion.Scope) error {
+	urls := make(map[string]string)
+	for i := range *in {
+		oldRequirement := &(*in)[i]
+		moduleKey, err := convertModuleReferenceToInternal(&oldRequirement.ModuleReference)
+		if err != nil {
+			return fmt.Errorf("error converting %T into %T: %w", in, out, err)
+		}
+
+		if _, ok := (*out)[moduleKey]; ok {
+			return fmt.Errorf("error converting %T into %T: duplicate requirement for %s", in, out, moduleKey)
+		}
+
+		newRequirement := api.NewRequirement()
+		if err := s.Convert(oldRequirement, newRequirement, 0); err != nil {
+			return err
+		}
+
+		if url, ok := urls[moduleKey.Repository]; ok && url != newRequirement.URL {
+			return fmt.Errorf("error converting %T into %T: requirements of repository %s specify different urls", in, out, moduleKey.Repository)
+		}
+		urls[moduleKey.Repository] = newRequirement.URL
+
+		(*out)[moduleKey] = newRequirement
+	}
+
+	return nil
+}
+
+func Convert_gem_ModuleKeyToRequirement_To_v1alpha2_Requirements(in *map[api.ModuleKey]*api.Requirement, out *[]Requirement, s conversion.Scope) error {
+	for moduleKey, newRequirement := range *in {
+		oldRequirement := &Requirement{}
+		if err := s.Convert(newRequirement, oldRequirement, 0); err != nil {
+			return err
+		}
+
+		oldRequirement.ModuleReference = convertModuleKeyToExternal(&moduleKey)
+		*out = append(*out, *oldRequirement)
+	}
+
+	// Sort by module so the serialized requirements do not depend on the map iteration order.
+	sort.Slice(*out, func(i, j int) bool {
+		return moduleReferenceLess(&(*out)[i].ModuleReference, &(*out)[j].ModuleReference)
+	})
+	return nil
+}
+
+func moduleReferenceLess(a, b *ModuleReference) bool {
+	aRepository, bRepository := emptyStringOrString(a.Repository), emptyStringOrString(b.Repository)
+	if aRepository != bRepository {
+		return aRepository < bRepository
+	}
+	return emptyStringOrString(a.Submodule) < emptyStringOrString(b.Submodule)
+}
+
+func Convert_v1alpha2_Locks_To_gem_ModuleKeyToLock(in *[]Lock, out *map[api.ModuleKey]*api.Lock, s conversion.Scope) error {
+	for i := range *in {
+		oldLock := &(*in)[i]
+		moduleKey, err := convertModuleReferenceToInternal(&oldLock.ModuleReference)
+		if err != nil {
+			return fmt.Errorf("error converting %T into %T: %w", in, out, err)
+		}
+
+		if _, ok := (*out)[moduleKey]; ok {
+			return fmt.Errorf("error converting %T into %T: duplicate lock for %s", in, out, moduleKey)
+		}
+
+		newLock := api.NewLock()
+		if err := s.Convert(oldLock, newLock, 0); err != nil {
+			return err
+		}
+
+		(*out)[moduleKey] = newLock
+	}
+
+	return nil
+}
+
+func Convert_gem_ModuleKeyToLock_To_v1alpha2_Locks(in *map[api.ModuleKey]*api.Lock, out *[]Lock, s conversion.Scope) error {
+	for moduleKey, newLock := range *in {
+		oldLock := &Lock{}
+		if err := s.Convert(newLock, oldLock, 0); err != nil {
+			return err
+		}
+
+		oldLock.ModuleReference = convertModuleKeyToExternal(&moduleKey)
+		*out = append(*out, *oldLock)
+	}
+
+	// Sort by module so the serialized locks do not depend on the map iteration order.
+	sort.Slice(*out, func(i, j int) bool {
+		return moduleReferenceLess(&(*out)[i].ModuleReference, &(*out)[j].ModuleReference)
+	})
+	return nil
+}
+
+func Convert_v1alpha2_Locks_To_gem_Locks(in *Locks, out *api.Locks, s conversion.Scope) error {
+	out.Locks = make(map[api.ModuleKey]*api.Lock)
+	if err := s.Convert(&in.Locks, &out.Locks, 0); err != nil {
+		return err
+	}
+
+	out.Header = api.LocksHeader{}
+	if in.Header != nil {
+		out.Header = api.LocksHeader{RequirementsDigest: in.Header.RequirementsDigest, GemVersion: in.Header.GemVersion}
+	}
+	return nil
+}
+
+func Convert_gem_Locks_To_v1alpha2_Locks(in *api.Locks, out *Locks, s conversion.Scope) error {
+	out.Locks = make([]Lock, 0, 0)
+	if err := s.Convert(&in.Locks, &out.Locks, 0); err != nil {
+		return err
+	}
+
+	out.Header = nil
+	if in.Header != (api.LocksHeader{}) {
+		out.Header = &LocksHeader{RequirementsDigest: in.Header.RequirementsDigest, GemVersion: in.Header.GemVersion}
+	}
+	return nil
+}
+
+// Convert_v1alpha2_Lock_To_gem_Lock converts everything but the module reference, which is the key the lock
+// is stored under.
+func Convert_v1alpha2_Lock_To_gem_Lock(in *Lock, out *api.Lock, s conversion.Scope) error {
+	if err := s.Convert(&in.Target, &out.Target, 0); err != nil {
+		return err
+	}
+	if err := s.Convert(&in.Resolved, &out.Resolved, 0); err != nil {
+		return err
+	}
+	out.Hash = in.Hash
+	out.TagHash = in.TagHash
+	out.Digest = in.Digest
+	out.Signer = in.Signer
+	out.CommitTime = timeOrZero(in.CommitTime)
+	out.Subject = in.Subject
+	out.ResolvedAt = timeOrZero(in.ResolvedAt)
+	out.GemVersion = in.GemVersion
+	return nil
+}
+
+// Convert_gem_Lock_To_v1alpha2_Lock converts everything but the module reference, which is the key the lock
+// is stored under.
+func Convert_gem_Lock_To_v1alpha2_Lock(in *api.Lock, out *Lock, s conversion.Scope) error {
+	if err := s.Convert(&in.Target, &out.Target, 0); err != nil {
+		return err
+	}
+	if err := s.Convert(&in.Resolved, &out.Resolved, 0); err != nil {
+		return err
+	}
+	out.Hash = in.Hash
+	out.TagHash = in.TagHash
+	out.Digest = in.Digest
+	out.Signer = in.Signer
+	out.CommitTime = nilOrTime(in.CommitTime)
+	out.Subject = in.Subject
+	out.ResolvedAt = nilOrTime(in.ResolvedAt)
+	out.GemVersion = in.GemVersion
+	return nil
+}
+
+func Convert_v1alpha2_HostCredential_To_gem_HostCredential(in *HostCredential, out *api.HostCredential, s conversion.Scope) error {
+	*out = api.HostCredential{Host: in.Host}
+	ct := 0
+	if in.SSHAgent != nil {
+		ct++
+		out.SSHAgent = &api.SSHAgentCredential{User: pointer.StringDerefOr(in.SSHAgent.User, DefaultSSHUser)}
+	}
+	if in.SSHKey != nil {
+		ct++
+		out.SSHKey = &api.SSHKeyCredential{
+			User:          pointer.StringDerefOr(in.SSHKey.User, DefaultSSHUser),
+			Path:          in.SSHKey.Path,
+			PassphraseEnv: emptyStringOrString(in.SSHKey.PassphraseEnv),
+		}
+	}
+	if in.Basic != nil {
+		ct++
+		out.Basic = &api.BasicCredential{UsernameEnv: in.Basic.UsernameEnv, PasswordEnv: in.Basic.PasswordEnv}
+	}
+	if in.Token != nil {
+		ct++
+		out.Token = &api.TokenCredential{TokenEnv: in.Token.TokenEnv}
+	}
+	if in.Netrc != nil {
+		ct++
+		out.Netrc = &api.NetrcCredential{Path: emptyStringOrString(in.Netrc.Path)}
+	}
+	if in.Helper != nil {
+		ct++
+		out.Helper = &api.CredentialHelper{Helper: emptyStringOrString(in.Helper.Helper)}
+	}
+
+	if ct != 1 {
+		return fmt.Errorf("error converting %T into %T: exactly one credential source has to be specified for host %q", in, out, in.Host)
+	}
+	return nil
+}
+
+func Convert_gem_HostCredential_To_v1alpha2_HostCredential(in *api.HostCredential, out *HostCredential, s conversion.Scope) error {
+	*out = HostCredential{Host: in.Host}
+	if in.SSHAgent != nil {
+		out.SSHAgent = &SSHAgentCredential{User: nilOrString(in.SSHAgent.User)}
+	}
+	if in.SSHKey != nil {
+		out.SSHKey = &SSHKeyCredential{
+			User:          nilOrString(in.SSHKey.User),
+			Path:          in.SSHKey.Path,
+			PassphraseEnv: nilOrString(in.SSHKey.PassphraseEnv),
+		}
+	}
+	if in.Basic != nil {
+		out.Basic = &BasicCredential{UsernameEnv: in.Basic.UsernameEnv, PasswordEnv: in.Basic.PasswordEnv}
+	}
+	if in.Token != nil {
+		out.Token = &TokenCredential{TokenEnv: in.Token.TokenEnv}
+	}
+	if in.Netrc != nil {
+		out.Netrc = &NetrcCredential{Path: nilOrString(in.Netrc.Path)}
+	}
+	if in.Helper != nil {
+		out.Helper = &CredentialHelper{Helper: nilOrString(in.Helper.Helper)}
+	}
+	return nil
+}
+
+func Convert_v1alpha2_Credentials_To_gem_Credentials(in *Credentials, out *api.Credentials, s conversion.Scope) error {
+	out.Credentials = make([]api.HostCredential, len(in.Credentials))
+	for i := range in.Credentials {
+		if err := s.Convert(&in.Credentials[i], &out.Credentials[i], 0); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func Convert_gem_Credentials_To_v1alpha2_Credentials(in *api.Credentials, out *Credentials, s conversion.Scope) error {
+	out.Credentials = make([]HostCredential, len(in.Credentials))
+	for i := range in.Credentials {
+		if err := s.Convert(&in.Credentials[i], &out.Credentials[i], 0); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func Convert_v1alpha2_DenyList_To_gem_DenyList(in *DenyList, out *api.DenyList, s conversion.Scope) error {
+	out.Denied = make(map[api.ModuleKey][]api.ExcludedVersion)
+	for i := range in.Denied {
+		denied := &in.Denied[i]
+		moduleKey, err := convertModuleReferenceToInternal(&denied.ModuleReference)
+		if err != nil {
+			return fmt.Errorf("error converting %T into %T: %w", in, out, err)
+		}
+		if len(denied.Versions) == 0 {
+			return fmt.Errorf("error converting %T into %T: no versions denied for %s", in, out, &moduleKey)
+		}
+
+		versions, err := convertExcludedVersionsToInternal(denied.Versions, emptyStringOrString(denied.Reason))
+		if err != nil {
+			return fmt.Errorf("error converting %T into %T: %s: %w", in, out, &moduleKey, err)
+		}
+		out.Denied[moduleKey] = append(out.Denied[moduleKey], versions...)
+	}
+	return nil
+}
+
+func Convert_gem_DenyList_To_v1alpha2_DenyList(in *api.DenyList, out *DenyList, s conversion.Scope) error {
+	out.Denied = nil
+	for moduleKey, versions := range in.Denied {
+		moduleKey := moduleKey
+		// Versions denied for the same reason are listed together, in their original order.
+		var byReason []DeniedVersions
+		for _, version := range versions {
+			i := 0
+			for i < len(byReason) && emptyStringOrString(byReason[i].Reason) != version.Reason {
+				i++
+			}
+			if i == len(byReason) {
+				byReason = append(byReason, DeniedVersions{
+					ModuleReference: convertModuleKeyToExternal(&moduleKey),
+					Reason:          nilOrString(version.Reason),
+				})
+			}
+			byReason[i].Versions = append(byReason[i].Versions, version.Version)
+		}
+		out.Denied = append(out.Denied, byReason...)
+	}
+
+	// Sort by module so the serialized deny list does not depend on the map iteration order.
+	sort.SliceStable(out.Denied, func(i, j int) bool {
+		return moduleReferenceLess(&out.Denied[i].ModuleReference, &out.Denied[j].ModuleReference)
+	})
+	return nil
+}
+
+func Convert_v1alpha2_Constraints_To_gem_Constraints(in *Constraints, out *api.Constraints, s conversion.Scope) error {
+	requires, err := convertModuleConstraintsToInternal(in.Requires)
+	if err != nil {
+		return fmt.Errorf("error converting %T into %T: %w", in, out, err)
+	}
+	gardener, err := convertVersionRangeToInternal(in.Gardener)
+	if err != nil {
+		return fmt.Errorf("error converting %T into %T: invalid gardener version range: %w", in, out, err)
+	}
+
+	out.Requires = requires
+	out.Gardener = gardener
+	return nil
+}
+
+func Convert_gem_Constraints_To_v1alpha2_Constraints(in *api.Constraints, out *Constraints, s conversion.Scope) error {
+	out.Requires = convertModuleConstraintsToExternal(in.Requires)
+	out.Gardener = nilOrString(in.Gardener)
+	return nil
+}
+
+func addConversionFuncs(scheme *runtime.Scheme) error {
+	// target
+	if err := scheme.AddConversionFunc((*Target)(nil), (*api.Target)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_Target_To_gem_Target(a.(*Target), b.(*api.Target), scope)
+	}); err != nil {
+		return err
+	}
+
+	if err := scheme.AddConversionFunc((*api.Target)(nil), (*Target)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_gem_Target_To_v1alpha2_Target(a.(*api.Target), b.(*Target), scope)
+	}); err != nil {
+		return err
+	}
+
+	// requirements
+	if err := scheme.AddConversionFunc((*Requirement)(nil), (*api.Requirement)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_Requirement_To_gem_Requirement(a.(*Requirement), b.(*api.Requirement), scope)
+	}); err != nil {
+		return err
+	}
+
+	if err := scheme.AddConversionFunc((*api.Requirement)(nil), (*Requirement)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_gem_Requirement_To_v1alpha2_Requirement(a.(*api.Requirement), b.(*Requirement), scope)
+	}); err != nil {
+		return err
+	}
+
+	if err := scheme.AddConversionFunc((*[]Requirement)(nil), (*map[api.ModuleKey]*api.Requirement)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_Requirements_To_gem_ModuleKeyToRequirement(a.(*[]Requirement), b.(*map[api.ModuleKey]*api.Requirement), scope)
+	}); err != nil {
+		return err
+	}
+
+	if err := scheme.AddConversionFunc((*map[api.ModuleKey]*api.Requirement)(nil), (*[]Requirement)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_gem_ModuleKeyToRequirement_To_v1alpha2_Requirements(a.(*map[api.ModuleKey]*api.Requirement), b.(*[]Requirement), scope)
+	}); err != nil {
+		return err
+	}
+
+	if err := scheme.AddConversionFunc((*Requirements)(nil), (*api.Requirements)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_Requirements_To_gem_Requirements(a.(*Requirements), b.(*api.Requirements), scope)
+	}); err != nil {
+		return err
+	}
+
+	if err := scheme.AddConversionFunc((*api.Requirements)(nil), (*Requirements)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_gem_Requirements_To_v1alpha2_Requirements(a.(*api.Requirements), b.(*Requirements), scope)
+	}); err != nil {
+		return err
+	}
+
+	// locks
+	if err := scheme.AddConversionFunc((*Lock)(nil), (*api.Lock)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_Lock_To_gem_Lock(a.(*Lock), b.(*api.Lock), scope)
+	}); err != nil {
+		return err
+	}
+
+	if err := scheme.AddConversionFunc((*api.Lock)(nil), (*Lock)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_gem_Lock_To_v1alpha2_Lock(a.(*api.Lock), b.(*Lock), scope)
+	}); err != nil {
+		return err
+	}
+
+	if err := scheme.AddConversionFunc((*[]Lock)(nil), (*map[api.ModuleKey]*api.Lock)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_Locks_To_gem_ModuleKeyToLock(a.(*[]Lock), b.(*map[api.ModuleKey]*api.Lock), scope)
+	}); err != nil {
+		return err
+	}
+
+	if err := scheme.AddConversionFunc((*map[api.ModuleKey]*api.Lock)(nil), (*[]Lock)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_gem_ModuleKeyToLock_To_v1alpha2_Locks(a.(*map[api.ModuleKey]*api.Lock), b.(*[]Lock), scope)
+	}); err != nil {
+		return err
+	}
+
+	if err := scheme.AddConversionFunc((*Locks)(nil), (*api.Locks)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_Locks_To_gem_Locks(a.(*Locks), b.(*api.Locks), scope)
+	}); err != nil {
+		return err
+	}
+
+	if err := scheme.AddConversionFunc((*api.Locks)(nil), (*Locks)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_gem_Locks_To_v1alpha2_Locks(a.(*api.Locks), b.(*Locks), scope)
+	}); err != nil {
+		return err
+	}
+
+	// credentials
+	if err := scheme.AddConversionFunc((*HostCredential)(nil), (*api.HostCredential)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_HostCredential_To_gem_HostCredential(a.(*HostCredential), b.(*api.HostCredential), scope)
+	}); err != nil {
+		return err
+	}
+
+	if err := scheme.AddConversionFunc((*api.HostCredential)(nil), (*HostCredential)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_gem_HostCredential_To_v1alpha2_HostCredential(a.(*api.HostCredential), b.(*HostCredential), scope)
+	}); err != nil {
+		return err
+	}
+
+	if err := scheme.AddConversionFunc((*Credentials)(nil), (*api.Credentials)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_Credentials_To_gem_Credentials(a.(*Credentials), b.(*api.Credentials), scope)
+	}); err != nil {
+		return err
+	}
+
+	if err := scheme.AddConversionFunc((*api.Credentials)(nil), (*Credentials)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_gem_Credentials_To_v1alpha2_Credentials(a.(*api.Credentials), b.(*Credentials), scope)
+	}); err != nil {
+		return err
+	}
+
+	// deny list
+	if err := scheme.AddConversionFunc((*DenyList)(nil), (*api.DenyList)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_DenyList_To_gem_DenyList(a.(*DenyList), b.(*api.DenyList), scope)
+	}); err != nil {
+		return err
+	}
+
+	if err := scheme.AddConversionFunc((*api.DenyList)(nil), (*DenyList)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_gem_DenyList_To_v1alpha2_DenyList(a.(*api.DenyList), b.(*DenyList), scope)
+	}); err != nil {
+		return err
+	}
+
+	// constraints
+	if err := scheme.AddConversionFunc((*Constraints)(nil), (*api.Constraints)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_Constraints_To_gem_Constraints(a.(*Constraints), b.(*api.Constraints), scope)
+	}); err != nil {
+		return err
+	}
+
+	if err := scheme.AddConversionFunc((*api.Constraints)(nil), (*Constraints)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_gem_Constraints_To_v1alpha2_Constraints(a.(*api.Constraints), b.(*Constraints), scope)
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,269 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha2
+
+import (
+	"encoding/json"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ModuleReference refers to a module either via its source or via its repository and submodule.
+type ModuleReference struct {
+	// Source is the shorthand `<repository>[//<submodule>]`, e.g. `gitlab.com/group/subgroup/project//provider-aws`.
+	// It is mutually exclusive with Repository and Submodule.
+	Source *string `json:"source,omitempty"`
+	// Repository is the repository of the module, e.g. `gitlab.com/group/subgroup/project`.
+	Repository *string `json:"repository,omitempty"`
+	// Submodule is the path of the module within the repository, if any.
+	Submodule *string `json:"submodule,omitempty"`
+}
+
+type Target struct {
+	Version   *string `json:"version,omitempty"`
+	Revision  *string `json:"revision,omitempty"`
+	Branch    *string `json:"branch,omitempty"`
+	TagPrefix *string `json:"tagPrefix,omitempty"`
+	// Prereleases controls whether a version may resolve to a prerelease, one of `exclude`, `include` or
+	// `only-if-newer`. By default, prereleases only match constraints that contain a prerelease themselves.
+	Prereleases *string `json:"prereleases,omitempty"`
+}
+
+type Requirement struct {
+	ModuleReference `json:",inline"`
+	// URL is the URL the repository is cloned from, if it differs from the repository, e.g. `ssh://git@host:2222/project.git`.
+	// All requirements of the same repository have to specify the same URL.
+	URL                *string `json:"url,omitempty"`
+	Target             `json:",inline"`
+	Filename           *string `json:"filename,omitempty"`
+	SubmoduleTagPrefix *bool   `json:"submoduleTagPrefix,omitempty"`
+	// UpdateStrategy limits how far an update may move the lock relative to the locked version,
+	// one of `patch`, `minor`, `major` or `latest-in-range` (default).
+	UpdateStrategy *string `json:"updateStrategy,omitempty"`
+	// Exclude are versions that must never be resolved although they satisfy the version, e.g. `1.4.2` or
+	// `{version: 1.4.2, reason: ...}`.
+	Exclude []ExcludedVersion `json:"exclude,omitempty"`
+	// Requires are the version ranges other modules have to satisfy, in addition to the ones declared by the module.
+	Requires []ModuleConstraint `json:"requires,omitempty"`
+	// Gardener is the version range of Gardener the module works with, in addition to the one declared by the module.
+	Gardener *string `json:"gardener,omitempty"`
+}
+
+// ExcludedVersion is a version that must never be resolved. Without a reason, it is written as plain version,
+// e.g. `1.4.2`, otherwise as object with `version` and `reason`.
+type ExcludedVersion struct {
+	// Version is the excluded version without tag prefix, e.g. `1.4.2`.
+	Version string `json:"version"`
+	// Reason explains why the version is excluded, e.g. a link to an advisory.
+	Reason *string `json:"reason,omitempty"`
+}
+
+// UnmarshalJSON accepts either a plain version or an object with version and reason.
+func (e *ExcludedVersion) UnmarshalJSON(data []byte) error {
+	var version string
+	if err := json.Unmarshal(data, &version); err == nil {
+		*e = ExcludedVersion{Version: version}
+		return nil
+	}
+
+	type excludedVersion ExcludedVersion
+	out := excludedVersion{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	*e = ExcludedVersion(out)
+	return nil
+}
+
+// MarshalJSON writes versions without a reason as plain versions.
+func (e ExcludedVersion) MarshalJSON() ([]byte, error) {
+	if e.Reason == nil {
+		return json.Marshal(e.Version)
+	}
+
+	type excludedVersion ExcludedVersion
+	return json.Marshal(excludedVersion(e))
+}
+
+// ModuleConstraint restricts the version of another module.
+type ModuleConstraint struct {
+	ModuleReference `json:",inline"`
+	// Version is the version range the module has to satisfy, e.g. `>=1.3.0`.
+	Version string `json:"version"`
+}
+
+// +kubebuilder:object:root=true
+
+// Requirements is a list of gardener extension requirements.
+type Requirements struct {
+	metav1.TypeMeta `json:",inline"`
+
+	Requirements []Requirement `json:"requirements,omitempty"`
+	// Prereleases is the prerelease policy of all version requirements of this file that do not specify one.
+	Prereleases *string `json:"prereleases,omitempty"`
+	// Includes are other requirements files that are merged into these requirements, in order. Requirements of
+	// later includes replace the ones of earlier includes, the own requirements replace all included ones.
+	Includes []Include `json:"includes,omitempty"`
+	// AllowedOverlaps are the resources that may be registered as primary by more than one extension.
+	AllowedOverlaps []ResourceOverlap `json:"allowedOverlaps,omitempty"`
+	// Profiles are named overlays of the requirements, e.g. for different landscapes.
+	Profiles []Profile `json:"profiles,omitempty"`
+	// GardenerVersion is the version of Gardener the extensions are deployed to, e.g. `1.15.0`. If it is not set,
+	// the Gardener version ranges of the modules are not checked.
+	GardenerVersion *string `json:"gardenerVersion,omitempty"`
+}
+
+// Include refers to a requirements file, either relative to the including file or in a repository
+// at a pinned revision.
+type Include struct {
+	Repository *string `json:"repository,omitempty"`
+	// Revision is the full commit hash the file is read at.
+	Revision *string `json:"revision,omitempty"`
+	Path     string  `json:"path"`
+}
+
+// Profile overlays the requirements it is part of.
+type Profile struct {
+	Name string `json:"name"`
+	// Requirements are added to the base requirements, replacing the base requirements of the same module.
+	Requirements []Requirement `json:"requirements,omitempty"`
+	// Exclude are the modules whose base requirements are removed.
+	Exclude []ModuleReference `json:"exclude,omitempty"`
+	// AllowedOverlaps are allowed in addition to the allowed overlaps of the base requirements.
+	AllowedOverlaps []ResourceOverlap `json:"allowedOverlaps,omitempty"`
+	// GardenerVersion replaces the Gardener version of the base requirements, if set.
+	GardenerVersion *string `json:"gardenerVersion,omitempty"`
+}
+
+// ResourceOverlap is a kind/type combination of an extension resource, e.g. `Infrastructure/aws`.
+type ResourceOverlap struct {
+	Kind string `json:"kind"`
+	Type string `json:"type"`
+}
+
+type Lock struct {
+	ModuleReference `json:",inline"`
+	Hash            string       `json:"hash"`
+	TagHash         string       `json:"tagHash,omitempty"`
+	Digest          string       `json:"digest,omitempty"`
+	Signer          string       `json:"signer,omitempty"`
+	CommitTime      *metav1.Time `json:"commitTime,omitempty"`
+	Subject         string       `json:"subject,omitempty"`
+	ResolvedAt      *metav1.Time `json:"resolvedAt,omitempty"`
+	GemVersion      string       `json:"gemVersion,omitempty"`
+	Target          `json:",inline"`
+	Resolved        Target `json:"resolved"`
+}
+
+// +kubebuilder:object:root=true
+
+// Locks is a resolved list of requirement targets with their hashes.
+type Locks struct {
+	metav1.TypeMeta `json:",inline"`
+
+	Header *LocksHeader `json:"header,omitempty"`
+	Locks  []Lock       `json:"locks,omitempty"`
+}
+
+// LocksHeader describes how the locks were resolved.
+type LocksHeader struct {
+	// RequirementsDigest is the digest of the requirements the locks were resolved from, e.g. `sha256:<hex>`.
+	RequirementsDigest string `json:"requirementsDigest,omitempty"`
+	// GemVersion is the version of gem that resolved the locks.
+	GemVersion string `json:"gemVersion,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// Constraints are declared by a module in its repository, next to its registration file. They restrict the
+// versions of other modules and of Gardener the module works with.
+type Constraints struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Requires are the version ranges other modules have to satisfy.
+	Requires []ModuleConstraint `json:"requires,omitempty"`
+	// Gardener is the version range of Gardener the module works with, e.g. `>=1.15`.
+	Gardener *string `json:"gardener,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DenyList lists versions of modules that must never be resolved, regardless of their requirements.
+type DenyList struct {
+	metav1.TypeMeta `json:",inline"`
+
+	Denied []DeniedVersions `json:"denied,omitempty"`
+}
+
+// DeniedVersions are versions of a module that must never be resolved.
+type DeniedVersions struct {
+	ModuleReference `json:",inline"`
+	// Versions are the denied versions without tag prefix, e.g. `1.4.2`.
+	Versions []string `json:"versions"`
+	// Reason explains why the versions are denied, e.g. a link to an advisory.
+	Reason *string `json:"reason,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// Credentials configures how to authenticate against the hosts of repositories.
+type Credentials struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Credentials are checked in order, the first one whose host pattern matches is used.
+	Credentials []HostCredential `json:"credentials,omitempty"`
+}
+
+// HostCredential is a credential for all repositories whose host (and optionally path) match the host pattern.
+// Exactly one of the credential sources has to be set.
+type HostCredential struct {
+	// Host is a pattern matched against the host of a repository, including its port if the repository
+	// URL has one, or against the host followed by a leading part of the path, e.g. `github.com/gardener`.
+	Host string `json:"host"`
+
+	SSHAgent *SSHAgentCredential `json:"sshAgent,omitempty"`
+	SSHKey   *SSHKeyCredential   `json:"sshKey,omitempty"`
+	Basic    *BasicCredential    `json:"basic,omitempty"`
+	Token    *TokenCredential    `json:"token,omitempty"`
+	Netrc    *NetrcCredential    `json:"netrc,omitempty"`
+	Helper   *CredentialHelper   `json:"helper,omitempty"`
+}
+
+type SSHAgentCredential struct {
+	User *string `json:"user,omitempty"`
+}
+
+type SSHKeyCredential struct {
+	User          *string `json:"user,omitempty"`
+	Path          string  `json:"path"`
+	PassphraseEnv *string `json:"passphraseEnv,omitempty"`
+}
+
+type BasicCredential struct {
+	UsernameEnv string `json:"usernameEnv"`
+	PasswordEnv string `json:"passwordEnv"`
+}
+
+type TokenCredential struct {
+	TokenEnv string `json:"tokenEnv"`
+}
+
+type NetrcCredential struct {
+	Path *string `json:"path,omitempty"`
+}
+
+type CredentialHelper struct {
+	Helper *string `json:"helper,omitempty"`
+}
@@ -22,11 +22,217 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BasicCredential) DeepCopyInto(out *BasicCredential) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BasicCredential.
+func (in *BasicCredential) DeepCopy() *BasicCredential {
+	if in == nil {
+		return nil
+	}
+	out := new(BasicCredential)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Constraints) DeepCopyInto(out *Constraints) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.Requires != nil {
+		in, out := &in.Requires, &out.Requires
+		*out = make(map[ModuleKey]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Constraints.
+func (in *Constraints) DeepCopy() *Constraints {
+	if in == nil {
+		return nil
+	}
+	out := new(Constraints)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Constraints) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CredentialHelper) DeepCopyInto(out *CredentialHelper) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CredentialHelper.
+func (in *CredentialHelper) DeepCopy() *CredentialHelper {
+	if in == nil {
+		return nil
+	}
+	out := new(CredentialHelper)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Credentials) DeepCopyInto(out *Credentials) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.Credentials != nil {
+		in, out := &in.Credentials, &out.Credentials
+		*out = make([]HostCredential, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Credentials.
+func (in *Credentials) DeepCopy() *Credentials {
+	if in == nil {
+		return nil
+	}
+	out := new(Credentials)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Credentials) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DenyList) DeepCopyInto(out *DenyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.Denied != nil {
+		in, out := &in.Denied, &out.Denied
+		*out = make(map[ModuleKey][]ExcludedVersion, len(*in))
+		for key, val := range *in {
+			var outVal []ExcludedVersion
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]ExcludedVersion, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DenyList.
+func (in *DenyList) DeepCopy() *DenyList {
+	if in == nil {
+		return nil
+	}
+	out := new(DenyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DenyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExcludedVersion) DeepCopyInto(out *ExcludedVersion) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExcludedVersion.
+func (in *ExcludedVersion) DeepCopy() *ExcludedVersion {
+	if in == nil {
+		return nil
+	}
+	out := new(ExcludedVersion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostCredential) DeepCopyInto(out *HostCredential) {
+	*out = *in
+	if in.SSHAgent != nil {
+		in, out := &in.SSHAgent, &out.SSHAgent
+		*out = new(SSHAgentCredential)
+		**out = **in
+	}
+	if in.SSHKey != nil {
+		in, out := &in.SSHKey, &out.SSHKey
+		*out = new(SSHKeyCredential)
+		**out = **in
+	}
+	if in.Basic != nil {
+		in, out := &in.Basic, &out.Basic
+		*out = new(BasicCredential)
+		**out = **in
+	}
+	if in.Token != nil {
+		in, out := &in.Token, &out.Token
+		*out = new(TokenCredential)
+		**out = **in
+	}
+	if in.Netrc != nil {
+		in, out := &in.Netrc, &out.Netrc
+		*out = new(NetrcCredential)
+		**out = **in
+	}
+	if in.Helper != nil {
+		in, out := &in.Helper, &out.Helper
+		*out = new(CredentialHelper)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostCredential.
+func (in *HostCredential) DeepCopy() *HostCredential {
+	if in == nil {
+		return nil
+	}
+	out := new(HostCredential)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Include) DeepCopyInto(out *Include) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Include.
+func (in *Include) DeepCopy() *Include {
+	if in == nil {
+		return nil
+	}
+	out := new(Include)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Lock) DeepCopyInto(out *Lock) {
 	*out = *in
-	out.Target = in.Target
-	out.Resolved = in.Resolved
+	in.Target.DeepCopyInto(&out.Target)
+	in.Resolved.DeepCopyInto(&out.Resolved)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Lock.
@@ -43,6 +249,7 @@ func (in *Lock) DeepCopy() *Lock {
 func (in *Locks) DeepCopyInto(out *Locks) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
+	out.Header = in.Header
 	if in.Locks != nil {
 		in, out := &in.Locks, &out.Locks
 		*out = make(map[ModuleKey]*Lock, len(*in))
@@ -53,7 +260,7 @@ func (in *Locks) DeepCopyInto(out *Locks) {
 			} else {
 				in, out := &val, &outVal
 				*out = new(Lock)
-				**out = **in
+				(*in).DeepCopyInto(*out)
 			}
 			(*out)[key] = outVal
 		}
@@ -78,6 +285,21 @@ func (in *Locks) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocksHeader) DeepCopyInto(out *LocksHeader) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocksHeader.
+func (in *LocksHeader) DeepCopy() *LocksHeader {
+	if in == nil {
+		return nil
+	}
+	out := new(LocksHeader)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ModuleKey) DeepCopyInto(out *ModuleKey) {
 	*out = *in
@@ -93,10 +315,72 @@ func (in *ModuleKey) DeepCopy() *ModuleKey {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetrcCredential) DeepCopyInto(out *NetrcCredential) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetrcCredential.
+func (in *NetrcCredential) DeepCopy() *NetrcCredential {
+	if in == nil {
+		return nil
+	}
+	out := new(NetrcCredential)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Profile) DeepCopyInto(out *Profile) {
+	*out = *in
+	if in.Requirements != nil {
+		in, out := &in.Requirements, &out.Requirements
+		*out = make(map[ModuleKey]*Requirement, len(*in))
+		for key, val := range *in {
+			var outVal *Requirement
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = new(Requirement)
+				(*in).DeepCopyInto(*out)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]ModuleKey, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedOverlaps != nil {
+		in, out := &in.AllowedOverlaps, &out.AllowedOverlaps
+		*out = make([]ResourceOverlap, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Profile.
+func (in *Profile) DeepCopy() *Profile {
+	if in == nil {
+		return nil
+	}
+	out := new(Profile)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Requirement) DeepCopyInto(out *Requirement) {
 	*out = *in
-	out.Target = in.Target
+	in.Target.DeepCopyInto(&out.Target)
+	if in.Requires != nil {
+		in, out := &in.Requires, &out.Requires
+		*out = make(map[ModuleKey]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Requirement.
@@ -123,7 +407,32 @@ func (in *Requirements) DeepCopyInto(out *Requirements) {
 			} else {
 				in, out := &val, &outVal
 				*out = new(Requirement)
-				**out = **in
+				(*in).DeepCopyInto(*out)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.Includes != nil {
+		in, out := &in.Includes, &out.Includes
+		*out = make([]Include, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedOverlaps != nil {
+		in, out := &in.AllowedOverlaps, &out.AllowedOverlaps
+		*out = make([]ResourceOverlap, len(*in))
+		copy(*out, *in)
+	}
+	if in.Profiles != nil {
+		in, out := &in.Profiles, &out.Profiles
+		*out = make(map[string]*Profile, len(*in))
+		for key, val := range *in {
+			var outVal *Profile
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = new(Profile)
+				(*in).DeepCopyInto(*out)
 			}
 			(*out)[key] = outVal
 		}
@@ -148,9 +457,59 @@ func (in *Requirements) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceOverlap) DeepCopyInto(out *ResourceOverlap) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceOverlap.
+func (in *ResourceOverlap) DeepCopy() *ResourceOverlap {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceOverlap)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SSHAgentCredential) DeepCopyInto(out *SSHAgentCredential) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SSHAgentCredential.
+func (in *SSHAgentCredential) DeepCopy() *SSHAgentCredential {
+	if in == nil {
+		return nil
+	}
+	out := new(SSHAgentCredential)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SSHKeyCredential) DeepCopyInto(out *SSHKeyCredential) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SSHKeyCredential.
+func (in *SSHKeyCredential) DeepCopy() *SSHKeyCredential {
+	if in == nil {
+		return nil
+	}
+	out := new(SSHKeyCredential)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Target) DeepCopyInto(out *Target) {
 	*out = *in
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]ExcludedVersion, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Target.
@@ -162,3 +521,18 @@ func (in *Target) DeepCopy() *Target {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TokenCredential) DeepCopyInto(out *TokenCredential) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TokenCredential.
+func (in *TokenCredential) DeepCopy() *TokenCredential {
+	if in == nil {
+		return nil
+	}
+	out := new(TokenCredential)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,326 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gem
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	gemapi "github.com/gardener/gem/pkg/gem/api"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/ssh"
+)
+
+type noAuth struct{}
+
+func (noAuth) Auth(_ context.Context, repositoryURL string) (transport.AuthMethod, error) {
+	return nil, nil
+}
+
+// NoAuth is an AuthProvider that always accesses repositories anonymously.
+var NoAuth AuthProvider = noAuth{}
+
+type credentialsAuthProvider struct {
+	mu          sync.Mutex
+	credentials *gemapi.Credentials
+	// cache caches the auth methods by credentialKey. Concurrent lookups of the same key are deduplicated
+	// without blocking the lookups of other keys.
+	cache *callCache
+}
+
+// credentialKey identifies the auth method obtained from the credential with the given index for a repository.
+type credentialKey struct {
+	index int
+	host  string
+	// path is only set for credential helpers, which may return credentials per path (credential.useHttpPath).
+	path string
+}
+
+// NewCredentialsAuthProvider creates a new CredentialsAuthProvider with the given credentials.
+// If credentials is nil, all repositories are accessed anonymously.
+func NewCredentialsAuthProvider(credentials *gemapi.Credentials) CredentialsAuthProvider {
+	return &credentialsAuthProvider{credentials: credentials, cache: newCallCache()}
+}
+
+func (c *credentialsAuthProvider) SetCredentials(credentials *gemapi.Credentials) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.credentials = credentials
+	c.cache = newCallCache()
+}
+
+// hostCredentialMatches checks whether the host pattern of the credential matches either the host
+// or the host followed by a leading part of the path of the given URL. The host includes the port if
+// the URL specifies one, so `git.example.com` does not match `ssh://git.example.com:2222/repo`.
+func hostCredentialMatches(credential *gemapi.HostCredential, u *url.URL) (bool, error) {
+	candidates := []string{u.Host}
+	candidate := u.Host
+	for _, segment := range strings.Split(strings.Trim(strings.TrimSuffix(u.Path, gitCacheEntrySuffix), "/"), "/") {
+		if segment == "" {
+			continue
+		}
+		candidate = candidate + "/" + segment
+		candidates = append(candidates, candidate)
+	}
+
+	for _, candidate := range candidates {
+		ok, err := path.Match(credential.Host, candidate)
+		if err != nil {
+			return false, fmt.Errorf("invalid host pattern %q: %w", credential.Host, err)
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (c *credentialsAuthProvider) Auth(ctx context.Context, repositoryURL string) (transport.AuthMethod, error) {
+	c.mu.Lock()
+	credentials, cache := c.credentials, c.cache
+	c.mu.Unlock()
+
+	if credentials == nil {
+		return nil, nil
+	}
+
+	u, err := parseRepositoryURL(repositoryURL)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range credentials.Credentials {
+		credential := &credentials.Credentials[i]
+		ok, err := hostCredentialMatches(credential, u)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		key := credentialKey{index: i, host: u.Host}
+		if credential.Helper != nil {
+			key.path = u.Path
+		}
+		auth, err := cache.do(ctx, key, func(ctx context.Context) (interface{}, error) {
+			return hostCredentialAuth(ctx, credential, u)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not obtain credentials for %s from %q: %w", u.Host, credential.Host, err)
+		}
+		return auth.(transport.AuthMethod), nil
+	}
+	return nil, nil
+}
+
+func hostCredentialAuth(ctx context.Context, credential *gemapi.HostCredential, u *url.URL) (transport.AuthMethod, error) {
+	switch {
+	case credential.SSHAgent != nil:
+		return ssh.NewSSHAgentAuth(credential.SSHAgent.User)
+	case credential.SSHKey != nil:
+		var passphrase string
+		if credential.SSHKey.PassphraseEnv != "" {
+			passphrase = os.Getenv(credential.SSHKey.PassphraseEnv)
+		}
+		return ssh.NewPublicKeysFromFile(credential.SSHKey.User, expandHome(credential.SSHKey.Path), passphrase)
+	case credential.Basic != nil:
+		password, err := lookupEnv(credential.Basic.PasswordEnv)
+		if err != nil {
+			return nil, err
+		}
+		return &http.BasicAuth{Username: os.Getenv(credential.Basic.UsernameEnv), Password: password}, nil
+	case credential.Token != nil:
+		token, err := lookupEnv(credential.Token.TokenEnv)
+		if err != nil {
+			return nil, err
+		}
+		return &http.TokenAuth{Token: token}, nil
+	case credential.Netrc != nil:
+		login, password, err := lookupNetrc(credential.Netrc.Path, u.Hostname())
+		if err != nil {
+			return nil, err
+		}
+		return &http.BasicAuth{Username: login, Password: password}, nil
+	case credential.Helper != nil:
+		username, password, err := fillCredential(ctx, credential.Helper.Helper, u)
+		if err != nil {
+			return nil, err
+		}
+		return &http.BasicAuth{Username: username, Password: password}, nil
+	default:
+		return nil, fmt.Errorf("no credential source specified")
+	}
+}
+
+func lookupEnv(name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok || value == "" {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
+
+func expandHome(filename string) string {
+	if filename != "~" && !strings.HasPrefix(filename, "~/") {
+		return filename
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filename
+	}
+	return filepath.Join(home, strings.TrimPrefix(filename, "~"))
+}
+
+func defaultNetrcPath() string {
+	if filename := os.Getenv("NETRC"); filename != "" {
+		return filename
+	}
+	return expandHome("~/.netrc")
+}
+
+type netrcEntry struct {
+	machine   string
+	isDefault bool
+	login     string
+	password  string
+}
+
+func parseNetrc(data string) []netrcEntry {
+	var (
+		entries []netrcEntry
+		fields  = strings.Fields(data)
+	)
+	for i := 0; i < len(fields); i++ {
+		switch key := fields[i]; key {
+		case "machine":
+			entries = append(entries, netrcEntry{})
+			if i+1 < len(fields) {
+				i++
+				entries[len(entries)-1].machine = fields[i]
+			}
+		case "default":
+			entries = append(entries, netrcEntry{isDefault: true})
+		case "login", "password", "account":
+			if i+1 >= len(fields) {
+				return entries
+			}
+			i++
+			if len(entries) == 0 {
+				continue
+			}
+
+			entry := &entries[len(entries)-1]
+			switch key {
+			case "login":
+				entry.login = fields[i]
+			case "password":
+				entry.password = fields[i]
+			}
+		case "macdef":
+			// Macro definitions extend to the next empty line and are of no interest here.
+			return entries
+		}
+	}
+	return entries
+}
+
+// lookupNetrc returns the login and password for the given host from the netrc file.
+// If there is no entry for the host, the default entry is used, if any.
+func lookupNetrc(filename, host string) (login, password string, err error) {
+	if filename == "" {
+		filename = defaultNetrcPath()
+	}
+
+	data, err := ioutil.ReadFile(expandHome(filename))
+	if err != nil {
+		return "", "", err
+	}
+
+	var fallback *netrcEntry
+	entries := parseNetrc(string(data))
+	for i := range entries {
+		entry := &entries[i]
+		if entry.machine == host {
+			return entry.login, entry.password, nil
+		}
+		if entry.isDefault && fallback == nil {
+			fallback = entry
+		}
+	}
+
+	if fallback != nil {
+		return fallback.login, fallback.password, nil
+	}
+	return "", "", fmt.Errorf("no entry for %s in %s", host, filename)
+}
+
+// fillCredential obtains a username and password via `git credential fill`. If helper is not empty,
+// only the given credential helper is used instead of the ones configured for git.
+func fillCredential(ctx context.Context, helper string, u *url.URL) (username, password string, err error) {
+	var args []string
+	if helper != "" {
+		args = append(args, "-c", "credential.helper=", "-c", "credential.helper="+helper)
+	}
+	args = append(args, "credential", "fill")
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("protocol=%s\nhost=%s\npath=%s\n\n", u.Scheme, u.Host, strings.TrimPrefix(u.Path, "/")))
+
+	out, err := cmd.Output()
+	if err != nil {
+		// The output may contain secrets, hence only the error is reported.
+		return "", "", fmt.Errorf("git credential fill failed: %w", err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		key, value := splitCredentialLine(scanner.Text())
+		switch key {
+		case "username":
+			username = value
+		case "password":
+			password = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", "", err
+	}
+	if password == "" {
+		return "", "", fmt.Errorf("git credential fill did not return a password")
+	}
+	return username, password, nil
+}
+
+func splitCredentialLine(line string) (string, string) {
+	idx := strings.IndexByte(line, '=')
+	if idx < 0 {
+		return line, ""
+	}
+	return line[:idx], line[idx+1:]
+}
@@ -14,132 +14,268 @@
 
 package gem
 
-import "io"
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
 
-type fileKey struct {
-	hash string
-	path string
+// call is a single, possibly still running, invocation of a function.
+type call struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+	// waiters is the number of callers waiting for the call.
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// callCache caches the results of successful calls and deduplicates concurrent calls for the same key.
+// Failed calls are not cached, so they are retried on the next invocation.
+type callCache struct {
+	mu    sync.Mutex
+	calls map[interface{}]*call
+}
+
+func newCallCache() *callCache {
+	return &callCache{calls: make(map[interface{}]*call)}
+}
+
+// detachedContext keeps the values of its parent but is never done, so that a call shared by several callers
+// does not depend on the context of the one that started it.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+// do returns the result of fn for the given key. fn runs on a context that is detached from the callers' ones and
+// only canceled once no caller waits for it anymore. Every caller gives up waiting once its own context is done.
+func (c *callCache) do(ctx context.Context, key interface{}, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	c.mu.Lock()
+	cl, ok := c.calls[key]
+	if !ok {
+		callCtx, cancel := context.WithCancel(detachedContext{ctx})
+		cl = &call{done: make(chan struct{}), cancel: cancel}
+		c.calls[key] = cl
+		go c.run(callCtx, key, cl, fn)
+	}
+	cl.waiters++
+	c.mu.Unlock()
+
+	select {
+	case <-cl.done:
+		return cl.value, cl.err
+	case <-ctx.Done():
+		c.abandon(key, cl)
+		return nil, ctx.Err()
+	}
+}
+
+func (c *callCache) run(ctx context.Context, key interface{}, cl *call, fn func(ctx context.Context) (interface{}, error)) {
+	defer cl.cancel()
+	value, err := fn(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cl.value, cl.err = value, err
+	if err != nil && c.calls[key] == cl {
+		delete(c.calls, key)
+	}
+	close(cl.done)
+}
+
+// abandon stops waiting for the given call. The call is canceled if no other caller waits for it, the next
+// caller then starts it anew.
+func (c *callCache) abandon(key interface{}, cl *call) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cl.waiters--
+	if cl.waiters > 0 {
+		return
+	}
+	select {
+	case <-cl.done:
+	default:
+		if c.calls[key] == cl {
+			delete(c.calls, key)
+		}
+		cl.cancel()
+	}
+}
+
+type (
+	revisionKey string
+	branchKey   string
+	versionsKey struct{}
+	latestKey   struct{}
+	fileKey     struct {
+		hash string
+		path string
+	}
+	signatureKey  string
+	objectInfoKey string
+)
+
+type signedPayload struct {
+	signature string
+	payload   []byte
 }
 
 type cachingRepository struct {
-	repository    Repository
-	revisionCache map[string]string
-	branchCache   map[string]string
-	versionsCache *[]RepositoryVersion
-	hasFileCache  map[fileKey]bool
-	latestCache   *string
+	repository Repository
+	cache      *callCache
 }
 
 func NewCachingRepository(repository Repository) Repository {
 	return &cachingRepository{
-		repository:    repository,
-		revisionCache: make(map[string]string),
-		branchCache:   make(map[string]string),
-		versionsCache: nil,
-		latestCache:   nil,
-		hasFileCache:  make(map[fileKey]bool),
+		repository: repository,
+		cache:      newCallCache(),
 	}
 }
 
 func (c *cachingRepository) Revision(name string) (string, error) {
-	hash, ok := c.revisionCache[name]
-	if ok {
-		return hash, nil
-	}
+	return c.RevisionContext(context.Background(), name)
+}
 
-	hash, err := c.repository.Revision(name)
+func (c *cachingRepository) RevisionContext(ctx context.Context, name string) (string, error) {
+	hash, err := c.cache.do(ctx, revisionKey(name), func(ctx context.Context) (interface{}, error) {
+		return c.repository.RevisionContext(ctx, name)
+	})
 	if err != nil {
 		return "", err
 	}
-
-	c.revisionCache[name] = hash
-	return hash, nil
+	return hash.(string), nil
 }
 
 func (c *cachingRepository) Branch(name string) (string, error) {
-	hash, ok := c.branchCache[name]
-	if ok {
-		return hash, nil
-	}
+	return c.BranchContext(context.Background(), name)
+}
 
-	hash, err := c.repository.Branch(name)
+func (c *cachingRepository) BranchContext(ctx context.Context, name string) (string, error) {
+	hash, err := c.cache.do(ctx, branchKey(name), func(ctx context.Context) (interface{}, error) {
+		return c.repository.BranchContext(ctx, name)
+	})
 	if err != nil {
 		return "", err
 	}
-
-	c.branchCache[name] = hash
-	return hash, nil
+	return hash.(string), nil
 }
 
 func (c *cachingRepository) Versions() ([]RepositoryVersion, error) {
-	if c.versionsCache != nil {
-		return *c.versionsCache, nil
-	}
+	return c.VersionsContext(context.Background())
+}
 
-	versions, err := c.repository.Versions()
+func (c *cachingRepository) VersionsContext(ctx context.Context) ([]RepositoryVersion, error) {
+	versions, err := c.cache.do(ctx, versionsKey{}, func(ctx context.Context) (interface{}, error) {
+		return c.repository.VersionsContext(ctx)
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	c.versionsCache = &versions
-	return versions, nil
+	return versions.([]RepositoryVersion), nil
 }
 
 func (c *cachingRepository) Latest() (string, error) {
-	if c.latestCache != nil {
-		return *c.latestCache, nil
-	}
+	return c.LatestContext(context.Background())
+}
 
-	latest, err := c.repository.Latest()
+func (c *cachingRepository) LatestContext(ctx context.Context) (string, error) {
+	latest, err := c.cache.do(ctx, latestKey{}, func(ctx context.Context) (interface{}, error) {
+		return c.repository.LatestContext(ctx)
+	})
 	if err != nil {
 		return "", err
 	}
-
-	c.latestCache = &latest
-	return latest, nil
+	return latest.(string), nil
 }
 
 func (c *cachingRepository) File(hash, path string) (io.Reader, error) {
-	return c.repository.File(hash, path)
+	return c.FileContext(context.Background(), hash, path)
+}
+
+func (c *cachingRepository) FileContext(ctx context.Context, hash, path string) (io.Reader, error) {
+	return c.repository.FileContext(ctx, hash, path)
 }
 
 func (c *cachingRepository) HasFile(hash, path string) (bool, error) {
-	if hasFile, ok := c.hasFileCache[fileKey{hash, path}]; ok {
-		return hasFile, nil
-	}
+	return c.HasFileContext(context.Background(), hash, path)
+}
 
-	hasFile, err := c.repository.HasFile(hash, path)
+func (c *cachingRepository) HasFileContext(ctx context.Context, hash, path string) (bool, error) {
+	hasFile, err := c.cache.do(ctx, fileKey{hash, path}, func(ctx context.Context) (interface{}, error) {
+		return c.repository.HasFileContext(ctx, hash, path)
+	})
 	if err != nil {
 		return false, err
 	}
+	return hasFile.(bool), nil
+}
+
+func (c *cachingRepository) Signature(hash string) (string, []byte, error) {
+	return c.SignatureContext(context.Background(), hash)
+}
+
+func (c *cachingRepository) SignatureContext(ctx context.Context, hash string) (string, []byte, error) {
+	signed, err := c.cache.do(ctx, signatureKey(hash), func(ctx context.Context) (interface{}, error) {
+		signature, payload, err := c.repository.SignatureContext(ctx, hash)
+		return signedPayload{signature, payload}, err
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return signed.(signedPayload).signature, signed.(signedPayload).payload, nil
+}
+
+func (c *cachingRepository) ObjectInfo(hash string) (*ObjectInfo, error) {
+	return c.ObjectInfoContext(context.Background(), hash)
+}
 
-	c.hasFileCache[fileKey{hash, path}] = hasFile
-	return hasFile, nil
+func (c *cachingRepository) ObjectInfoContext(ctx context.Context, hash string) (*ObjectInfo, error) {
+	info, err := c.cache.do(ctx, objectInfoKey(hash), func(ctx context.Context) (interface{}, error) {
+		return c.repository.ObjectInfoContext(ctx, hash)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return info.(*ObjectInfo), nil
 }
 
 type repositoryRegistryCache struct {
-	registry                   RepositoryRegistry
-	repositoryNameToRepository map[string]Repository
+	registry RepositoryRegistry
+	cache    *callCache
 }
 
+// NewRepositoryRegistryCache creates a new RepositoryRegistry that caches the repositories of the given registry.
+// Concurrent requests for the same repository are deduplicated.
 func NewRepositoryRegistryCache(registry RepositoryRegistry) RepositoryRegistry {
-	return &repositoryRegistryCache{registry, make(map[string]Repository)}
+	return &repositoryRegistryCache{registry, newCallCache()}
 }
 
-func (r repositoryRegistryCache) Repository(name string) (Repository, error) {
-	repository, ok := r.repositoryNameToRepository[name]
-	if ok {
-		return repository, nil
-	}
+func (r *repositoryRegistryCache) Repository(name string) (Repository, error) {
+	return r.RepositoryContext(context.Background(), name)
+}
 
-	var err error
-	repository, err = r.registry.Repository(name)
+func (r *repositoryRegistryCache) RepositoryContext(ctx context.Context, name string) (Repository, error) {
+	repository, err := r.cache.do(ctx, name, func(ctx context.Context) (interface{}, error) {
+		return r.registry.RepositoryContext(ctx, name)
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	r.repositoryNameToRepository[name] = repository
-	return repository, nil
+	return repository.(Repository), nil
 }
 
 type repositoryRegistryCachingRepositoryWrapper struct {
@@ -151,7 +287,11 @@ func NewRepositoryRegistryCachingRepositoryWrapper(registry RepositoryRegistry)
 }
 
 func (c *repositoryRegistryCachingRepositoryWrapper) Repository(name string) (Repository, error) {
-	repository, err := c.registry.Repository(name)
+	return c.RepositoryContext(context.Background(), name)
+}
+
+func (c *repositoryRegistryCachingRepositoryWrapper) RepositoryContext(ctx context.Context, name string) (Repository, error) {
+	repository, err := c.registry.RepositoryContext(ctx, name)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,142 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gem
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCallCacheDo(t *testing.T) {
+	t.Run("shared call outlives the caller that started it", func(t *testing.T) {
+		var (
+			cache   = newCallCache()
+			release = make(chan struct{})
+			started = make(chan struct{})
+		)
+		fn := func(ctx context.Context) (interface{}, error) {
+			close(started)
+			select {
+			case <-release:
+				return "value", nil
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		firstCtx, cancelFirst := context.WithCancel(context.Background())
+		firstErr := make(chan error, 1)
+		go func() {
+			_, err := cache.do(firstCtx, "key", fn)
+			firstErr <- err
+		}()
+		<-started
+
+		second := make(chan interface{}, 1)
+		go func() {
+			value, err := cache.do(context.Background(), "key", fn)
+			if err != nil {
+				second <- err
+				return
+			}
+			second <- value
+		}()
+		waitForWaiters(t, cache, "key", 2)
+
+		cancelFirst()
+		if err := <-firstErr; !errors.Is(err, context.Canceled) {
+			t.Fatalf("first caller returned %v, want %v", err, context.Canceled)
+		}
+
+		close(release)
+		if value := <-second; value != "value" {
+			t.Fatalf("second caller returned %v, want %q", value, "value")
+		}
+	})
+
+	t.Run("abandoned call is canceled and started anew", func(t *testing.T) {
+		var (
+			cache    = newCallCache()
+			canceled = make(chan struct{})
+			calls    int
+		)
+		blocking := func(ctx context.Context) (interface{}, error) {
+			calls++
+			<-ctx.Done()
+			close(canceled)
+			return nil, ctx.Err()
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if _, err := cache.do(ctx, "key", blocking); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("do returned %v, want %v", err, context.DeadlineExceeded)
+		}
+		select {
+		case <-canceled:
+		case <-time.After(time.Second):
+			t.Fatal("abandoned call was not canceled")
+		}
+
+		value, err := cache.do(context.Background(), "key", func(ctx context.Context) (interface{}, error) {
+			calls++
+			return "value", nil
+		})
+		if err != nil || value != "value" {
+			t.Fatalf("do returned %v, %v, want %q", value, err, "value")
+		}
+		if calls != 2 {
+			t.Fatalf("function was called %d times, want 2", calls)
+		}
+	})
+
+	t.Run("failed calls are not cached", func(t *testing.T) {
+		var (
+			cache = newCallCache()
+			fail  = func(ctx context.Context) (interface{}, error) { return nil, errors.New("failed") }
+		)
+		if _, err := cache.do(context.Background(), "key", fail); err == nil {
+			t.Fatal("do returned no error")
+		}
+		value, err := cache.do(context.Background(), "key", func(ctx context.Context) (interface{}, error) {
+			return "value", nil
+		})
+		if err != nil || value != "value" {
+			t.Fatalf("do returned %v, %v, want %q", value, err, "value")
+		}
+		if value, err = cache.do(context.Background(), "key", fail); err != nil || value != "value" {
+			t.Fatalf("do returned %v, %v, want the cached %q", value, err, "value")
+		}
+	})
+}
+
+func waitForWaiters(t *testing.T, cache *callCache, key interface{}, waiters int) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		cache.mu.Lock()
+		cl := cache.calls[key]
+		n := 0
+		if cl != nil {
+			n = cl.waiters
+		}
+		cache.mu.Unlock()
+		if n == waiters {
+			return
+		}
+	}
+	t.Fatalf("timed out waiting for %d waiters", waiters)
+}
@@ -0,0 +1,90 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gem
+
+import (
+	"sort"
+
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	gemapi "github.com/gardener/gem/pkg/gem/api"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ResourceConflict is a resource that is registered as primary by more than one extension.
+type ResourceConflict struct {
+	Resource   gemapi.ResourceOverlap
+	ModuleKeys []gemapi.ModuleKey
+}
+
+// primaryResources returns the kind/type combinations the given objects register as primary.
+func primaryResources(objs []runtime.Object) ([]gemapi.ResourceOverlap, error) {
+	var resources []gemapi.ResourceOverlap
+	for _, obj := range objs {
+		u, ok := obj.(runtime.Unstructured)
+		if !ok || obj.GetObjectKind().GroupVersionKind().Kind != controllerRegistrationKind {
+			continue
+		}
+
+		registration := &gardencorev1beta1.ControllerRegistration{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), registration); err != nil {
+			return nil, err
+		}
+
+		for _, resource := range registration.Spec.Resources {
+			if resource.Primary == nil || *resource.Primary {
+				resources = append(resources, gemapi.ResourceOverlap{Kind: resource.Kind, Type: resource.Type})
+			}
+		}
+	}
+	return resources, nil
+}
+
+// detectConflicts returns the resources that are registered as primary by more than one module, except for the
+// allowed overlaps of the requirements. The registrations are indexed like the sorted module keys of the
+// requirements. The conflicts are ordered by kind and type.
+func detectConflicts(requirements *gemapi.Requirements, registrations [][]runtime.Object) ([]ResourceConflict, error) {
+	allowed := make(map[gemapi.ResourceOverlap]bool, len(requirements.AllowedOverlaps))
+	for _, overlap := range requirements.AllowedOverlaps {
+		allowed[overlap] = true
+	}
+
+	moduleKeys := sortedModuleKeys(requirements)
+	owners := make(map[gemapi.ResourceOverlap][]gemapi.ModuleKey)
+	for i, objs := range registrations {
+		resources, err := primaryResources(objs)
+		if err != nil {
+			return nil, &ModuleError{ModuleKey: moduleKeys[i], Requirement: requirements.Requirements[moduleKeys[i]], Err: err}
+		}
+
+		for _, resource := range resources {
+			// A module may register the same resource more than once, e.g. in several registrations.
+			if keys := owners[resource]; len(keys) > 0 && keys[len(keys)-1] == moduleKeys[i] {
+				continue
+			}
+			owners[resource] = append(owners[resource], moduleKeys[i])
+		}
+	}
+
+	var conflicts []ResourceConflict
+	for resource, moduleKeys := range owners {
+		if len(moduleKeys) > 1 && !allowed[resource] {
+			conflicts = append(conflicts, ResourceConflict{resource, moduleKeys})
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].Resource.String() < conflicts[j].Resource.String()
+	})
+	return conflicts, nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gem
+
+import (
+	"reflect"
+	"testing"
+
+	gemapi "github.com/gardener/gem/pkg/gem/api"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// newTestRegistration returns a controller registration of the given kind/type resources.
+func newTestRegistration(resources ...gemapi.ResourceOverlap) runtime.Object {
+	var specResources []interface{}
+	for _, resource := range resources {
+		specResources = append(specResources, map[string]interface{}{"kind": resource.Kind, "type": resource.Type})
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "core.gardener.cloud/v1beta1",
+		"kind":       controllerRegistrationKind,
+		"metadata":   map[string]interface{}{"name": "test"},
+		"spec":       map[string]interface{}{"resources": specResources},
+	}}
+}
+
+func TestDetectConflicts(t *testing.T) {
+	var (
+		a   = gemapi.ModuleKey{Repository: "example.com/a"}
+		b   = gemapi.ModuleKey{Repository: "example.com/b"}
+		aws = gemapi.ResourceOverlap{Kind: "Infrastructure", Type: "aws"}
+		gcp = gemapi.ResourceOverlap{Kind: "Infrastructure", Type: "gcp"}
+	)
+
+	for _, tc := range []struct {
+		name string
+		// registrations are the registrations of a and b.
+		registrations [][]runtime.Object
+		allowed       []gemapi.ResourceOverlap
+		want          []ResourceConflict
+	}{
+		{
+			name:          "no overlap",
+			registrations: [][]runtime.Object{{newTestRegistration(aws)}, {newTestRegistration(gcp)}},
+		},
+		{
+			name:          "overlap",
+			registrations: [][]runtime.Object{{newTestRegistration(aws, gcp)}, {newTestRegistration(aws)}},
+			want:          []ResourceConflict{{Resource: aws, ModuleKeys: []gemapi.ModuleKey{a, b}}},
+		},
+		{
+			name:          "allowed overlap",
+			registrations: [][]runtime.Object{{newTestRegistration(aws)}, {newTestRegistration(aws)}},
+			allowed:       []gemapi.ResourceOverlap{aws},
+		},
+		{
+			name:          "module registering a resource more than once does not conflict with itself",
+			registrations: [][]runtime.Object{{newTestRegistration(aws, aws), newTestRegistration(aws)}, {newTestRegistration(gcp)}},
+		},
+		{
+			name:          "module registering a resource more than once is listed once",
+			registrations: [][]runtime.Object{{newTestRegistration(aws), newTestRegistration(aws)}, {newTestRegistration(aws)}},
+			want:          []ResourceConflict{{Resource: aws, ModuleKeys: []gemapi.ModuleKey{a, b}}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			requirements := &gemapi.Requirements{
+				Requirements:    map[gemapi.ModuleKey]*gemapi.Requirement{a: {}, b: {}},
+				AllowedOverlaps: tc.allowed,
+			}
+			got, err := detectConflicts(requirements, tc.registrations)
+			if err != nil {
+				t.Fatalf("detectConflicts: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("detectConflicts returned %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,600 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gem
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/pkg/errors"
+
+	gemapi "github.com/gardener/gem/pkg/gem/api"
+)
+
+// maxConstraintSolverSteps limits the number of candidates the constraint solver tries before giving up.
+const maxConstraintSolverSteps = 100000
+
+// candidate is a commit a module may resolve to.
+type candidate struct {
+	// version is the version tag of the commit if the module is required by version.
+	version *RepositoryVersion
+	hash    string
+}
+
+func (c *candidate) String() string {
+	if c.version != nil {
+		return c.version.Name
+	}
+	return c.hash
+}
+
+// constraint restricts the version of a module or, if moduleKey is nil, the one of Gardener.
+type constraint struct {
+	moduleKey    *gemapi.ModuleKey
+	versionRange string
+	constraints  *semver.Constraints
+	// fromRequirement is set if the constraint is declared by the requirement rather than by the module.
+	fromRequirement bool
+}
+
+func (c *constraint) target() string {
+	if c.moduleKey == nil {
+		return "Gardener"
+	}
+	return c.moduleKey.String()
+}
+
+// parseConstraints returns the given version ranges as constraints, sorted by the module they restrict.
+func parseConstraints(requires map[gemapi.ModuleKey]string, gardener string, fromRequirement bool) ([]constraint, error) {
+	var out []constraint
+	for moduleKey, versionRange := range requires {
+		moduleKey := moduleKey
+		constraints, err := semver.NewConstraint(versionRange)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version range %q for %s: %w", versionRange, &moduleKey, err)
+		}
+		out = append(out, constraint{&moduleKey, versionRange, constraints, fromRequirement})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].target() < out[j].target() })
+
+	if gardener != "" {
+		constraints, err := semver.NewConstraint(gardener)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version range %q for Gardener: %w", gardener, err)
+		}
+		out = append(out, constraint{nil, gardener, constraints, fromRequirement})
+	}
+	return out, nil
+}
+
+// constraintsFileKey identifies the constraints file at a path of a repository at a commit.
+type constraintsFileKey struct {
+	repositoryURL string
+	path          string
+	hash          string
+}
+
+// constrainedModule is a module whose version is chosen by the constraint solver.
+type constrainedModule struct {
+	moduleKey     gemapi.ModuleKey
+	requirement   *gemapi.Requirement
+	repositoryURL string
+	repository    Repository
+	// candidates are the commits the module may resolve to, the preferred one first.
+	candidates []candidate
+	// constraints are the constraints of the candidates by hash, once loaded.
+	constraints map[string][]constraint
+	// files caches the declared constraints by constraintsFileKey across modules and runs.
+	files *callCache
+}
+
+// constraintsAt returns the constraints of the module at the given candidate, i.e. the ones declared in the
+// constraints file next to its registration file and the ones of its requirement. The constraints file is
+// only read once per commit.
+func (m *constrainedModule) constraintsAt(ctx context.Context, c *candidate) ([]constraint, error) {
+	if constraints, ok := m.constraints[c.hash]; ok {
+		return constraints, nil
+	}
+
+	constraints, err := parseConstraints(m.requirement.Requires, m.requirement.Gardener, true)
+	if err != nil {
+		return nil, err
+	}
+
+	declared, err := m.declaredConstraints(ctx, c.hash)
+	if err != nil {
+		return nil, fmt.Errorf("%s at %s: %w", m.constraintsPath(), c, err)
+	}
+	if declared != nil {
+		fromFile, err := parseConstraints(declared.Requires, declared.Gardener, false)
+		if err != nil {
+			return nil, fmt.Errorf("%s at %s: %w", m.constraintsPath(), c, err)
+		}
+		constraints = append(fromFile, constraints...)
+	}
+
+	m.constraints[c.hash] = constraints
+	return constraints, nil
+}
+
+// constraintsPath returns the path of the constraints file next to the registration file of the module.
+func (m *constrainedModule) constraintsPath() string {
+	return filepath.Join(filepath.Dir(optSubmodulePath(m.moduleKey.Submodule, m.requirement.Filename)), DefaultConstraintsPath)
+}
+
+// declaredConstraints returns the constraints declared in the constraints file of the module at the given
+// hash, or nil if there is none.
+func (m *constrainedModule) declaredConstraints(ctx context.Context, hash string) (*gemapi.Constraints, error) {
+	path := m.constraintsPath()
+	declared, err := m.files.do(ctx, constraintsFileKey{m.repositoryURL, path, hash}, func(ctx context.Context) (interface{}, error) {
+		return m.readConstraints(ctx, hash, path)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return declared.(*gemapi.Constraints), nil
+}
+
+// readConstraints reads the constraints file at the given hash and path. It returns nil if there is none.
+func (m *constrainedModule) readConstraints(ctx context.Context, hash, path string) (*gemapi.Constraints, error) {
+	ok, err := m.repository.HasFileContext(ctx, hash, path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error checking for file with hash %s at %s", hash, path)
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	reader, err := m.repository.FileContext(ctx, hash, path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting file with hash %s at %s", hash, path)
+	}
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading file with hash %s at %s", hash, path)
+	}
+	return LoadConstraints(data)
+}
+
+// constraintConflict is a constraint that ruled out candidates of the module it restricts.
+type constraintConflict struct {
+	module     *constrainedModule
+	constraint constraint
+	// versions are the candidates of the module that declare the constraint.
+	versions []string
+}
+
+// constraintSolver chooses a candidate for every module such that the constraints of all chosen candidates
+// are satisfied. It tries the candidates of the modules in order and backtracks on conflicts.
+type constraintSolver struct {
+	modules  []*constrainedModule
+	index    map[gemapi.ModuleKey]int
+	gardener *semver.Version
+
+	chosen    []int
+	steps     int
+	conflicts map[string]*constraintConflict
+}
+
+func newConstraintSolver(modules []*constrainedModule, gardenerVersion string) (*constraintSolver, error) {
+	s := &constraintSolver{
+		modules:   modules,
+		index:     make(map[gemapi.ModuleKey]int, len(modules)),
+		chosen:    make([]int, len(modules)),
+		conflicts: make(map[string]*constraintConflict),
+	}
+	for i, m := range modules {
+		s.index[m.moduleKey] = i
+	}
+	if gardenerVersion != "" {
+		version, err := semver.NewVersion(gardenerVersion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Gardener version %q: %w", gardenerVersion, err)
+		}
+		s.gardener = version
+	}
+	return s, nil
+}
+
+// satisfies reports whether the chosen candidate of the i-th module satisfies the given constraint. Constraints
+// on modules that are not required by version cannot be verified and are considered satisfied, see
+// unverifiableConstraints.
+func (s *constraintSolver) satisfies(i int, c *constraint) bool {
+	version := s.modules[i].candidates[s.chosen[i]].version
+	if version == nil {
+		return true
+	}
+	return matchesVersion(c.constraints, &version.Version, s.modules[i].requirement.Target.Prereleases)
+}
+
+// unverifiableConstraints describes the constraints of the chosen candidates on modules that are not required
+// by version, sorted.
+func (s *constraintSolver) unverifiableConstraints(ctx context.Context) ([]string, error) {
+	var out []string
+	for i, m := range s.modules {
+		c := &m.candidates[s.chosen[i]]
+		constraints, err := m.constraintsAt(ctx, c)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", &m.moduleKey, err)
+		}
+
+		for k := range constraints {
+			constraint := &constraints[k]
+			if constraint.moduleKey == nil {
+				continue
+			}
+			j, required := s.index[*constraint.moduleKey]
+			if !required || j == i || s.modules[j].candidates[s.chosen[j]].version != nil {
+				continue
+			}
+
+			declarer := fmt.Sprintf("%s %s", &m.moduleKey, c)
+			if constraint.fromRequirement {
+				declarer = fmt.Sprintf("the requirement of %s", &m.moduleKey)
+			}
+			out = append(out, fmt.Sprintf("%s requires %s %s, which cannot be verified since it is not required by version", declarer, constraint.target(), constraint.versionRange))
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// conflict records that the constraint of the from-th module ruled out a candidate.
+func (s *constraintSolver) conflict(from int, c *constraint) {
+	m := s.modules[from]
+	key := fmt.Sprintf("%s\x00%t\x00%s\x00%s", &m.moduleKey, c.fromRequirement, c.target(), c.versionRange)
+	conflict, ok := s.conflicts[key]
+	if !ok {
+		conflict = &constraintConflict{module: m, constraint: *c}
+		s.conflicts[key] = conflict
+	}
+
+	version := m.candidates[s.chosen[from]].String()
+	for _, v := range conflict.versions {
+		if v == version {
+			return
+		}
+	}
+	conflict.versions = append(conflict.versions, version)
+}
+
+// check checks the chosen candidate of the i-th module against the chosen candidates of the modules before it
+// and against the Gardener version. It returns whether the candidate is consistent and, if not, the indices of
+// the modules before it that take part in the conflicts. The constraints of the modules before it are checked
+// first, so the constraints of the candidate are only loaded if the candidate is not ruled out by them.
+func (s *constraintSolver) check(ctx context.Context, i int) (bool, conflictSet, error) {
+	var (
+		m         = s.modules[i]
+		culprits  = conflictSet{}
+		candidate = &m.candidates[s.chosen[i]]
+	)
+
+	for j := 0; j < i; j++ {
+		other := s.modules[j]
+		theirs, err := other.constraintsAt(ctx, &other.candidates[s.chosen[j]])
+		if err != nil {
+			return false, nil, fmt.Errorf("%s: %w", &other.moduleKey, err)
+		}
+		for k := range theirs {
+			c := &theirs[k]
+			if c.moduleKey == nil || *c.moduleKey != m.moduleKey {
+				continue
+			}
+			if !s.satisfies(i, c) {
+				s.conflict(j, c)
+				culprits[j] = struct{}{}
+			}
+		}
+	}
+	if len(culprits) > 0 {
+		return false, culprits, nil
+	}
+
+	own, err := m.constraintsAt(ctx, candidate)
+	if err != nil {
+		return false, nil, fmt.Errorf("%s: %w", &m.moduleKey, err)
+	}
+	ok := true
+	for k := range own {
+		c := &own[k]
+		if c.moduleKey == nil {
+			if s.gardener != nil && !c.constraints.Check(s.gardener) {
+				s.conflict(i, c)
+				ok = false
+			}
+			continue
+		}
+
+		j, required := s.index[*c.moduleKey]
+		if !required || j >= i {
+			continue
+		}
+		if !s.satisfies(j, c) {
+			s.conflict(i, c)
+			ok = false
+			culprits[j] = struct{}{}
+		}
+	}
+	return ok, culprits, nil
+}
+
+// conflictSet are the indices of modules whose chosen candidates take part in a conflict.
+type conflictSet map[int]struct{}
+
+// max returns the greatest index of the set, or -1 if it is empty.
+func (c conflictSet) max() int {
+	max := -1
+	for i := range c {
+		if i > max {
+			max = i
+		}
+	}
+	return max
+}
+
+// assign chooses candidates for the modules from the i-th one on using conflict-directed backjumping. If no
+// choice succeeds, it returns the modules before the i-th one that take part in the conflicts: the search
+// then jumps back to the last of them rather than to the previous module, so the candidates of modules that
+// are not involved are never tried.
+func (s *constraintSolver) assign(ctx context.Context, i int) (bool, conflictSet, error) {
+	if i == len(s.modules) {
+		return true, nil, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return false, nil, err
+	}
+
+	conflicts := conflictSet{}
+	for j := range s.modules[i].candidates {
+		s.steps++
+		if s.steps > maxConstraintSolverSteps {
+			return false, nil, fmt.Errorf("could not find a combination of versions satisfying all constraints within %d steps", maxConstraintSolverSteps)
+		}
+
+		s.chosen[i] = j
+		ok, culprits, err := s.check(ctx, i)
+		if err != nil {
+			return false, nil, err
+		}
+		if !ok {
+			for k := range culprits {
+				conflicts[k] = struct{}{}
+			}
+			continue
+		}
+
+		ok, deeper, err := s.assign(ctx, i+1)
+		if err != nil || ok {
+			return ok, nil, err
+		}
+		if _, involved := deeper[i]; !involved {
+			return false, deeper, nil
+		}
+		for k := range deeper {
+			if k != i {
+				conflicts[k] = struct{}{}
+			}
+		}
+	}
+	return false, conflicts, nil
+}
+
+// describeCandidates lists the names of the given candidates, abbreviating long lists.
+func describeCandidates(candidates []candidate) string {
+	const max = 5
+
+	names := make([]string, 0, max+1)
+	for i := range candidates {
+		if i == max {
+			names = append(names, fmt.Sprintf("and %d more", len(candidates)-max))
+			break
+		}
+		names = append(names, candidates[i].String())
+	}
+	return strings.Join(names, ", ")
+}
+
+// explain describes the given conflict.
+func (s *constraintSolver) explain(conflict *constraintConflict) string {
+	declarer := fmt.Sprintf("%s %s", &conflict.module.moduleKey, strings.Join(conflict.versions, ", "))
+	if conflict.constraint.fromRequirement {
+		declarer = fmt.Sprintf("the requirement of %s", &conflict.module.moduleKey)
+	}
+
+	c := &conflict.constraint
+	if c.moduleKey == nil {
+		return fmt.Sprintf("%s requires Gardener %s, but Gardener is %s", declarer, c.versionRange, s.gardener.Original())
+	}
+	target := s.modules[s.index[*c.moduleKey]]
+	return fmt.Sprintf("%s requires %s %s, candidates are %s", declarer, c.target(), c.versionRange, describeCandidates(target.candidates))
+}
+
+// solve returns the chosen candidate of every module. If there is no consistent choice, it returns a
+// ConstraintConflictError explaining the conflicts encountered during the search.
+func (s *constraintSolver) solve(ctx context.Context) (map[gemapi.ModuleKey]*candidate, error) {
+	ok, _, err := s.assign(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		conflicts := make([]string, 0, len(s.conflicts))
+		for _, conflict := range s.conflicts {
+			conflicts = append(conflicts, s.explain(conflict))
+		}
+		sort.Strings(conflicts)
+		return nil, &ConstraintConflictError{Conflicts: conflicts}
+	}
+
+	chosen := make(map[gemapi.ModuleKey]*candidate, len(s.modules))
+	for i, m := range s.modules {
+		chosen[m.moduleKey] = &m.candidates[s.chosen[i]]
+	}
+	return chosen, nil
+}
+
+// constrainedModule returns the module with the candidates it may resolve to, greatest version first. A lock
+// that satisfies the requirement is preferred unless the module is updated, in which case the version range
+// is narrowed according to the update strategy like Ensure does. Modules not required by version only have
+// the commit their target resolves to as candidate.
+func (g *gem) constrainedModule(ctx context.Context, moduleKey gemapi.ModuleKey, requirement *gemapi.Requirement, lock *gemapi.Lock, update bool) (*constrainedModule, error) {
+	url := repositoryURL(moduleKey, requirement)
+	repository, err := g.registry.RepositoryContext(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	requirement = effectiveRequirement(moduleKey.Submodule, requirement)
+	m := &constrainedModule{
+		moduleKey:     moduleKey,
+		requirement:   requirement,
+		repositoryURL: url,
+		repository:    repository,
+		constraints:   make(map[string][]constraint),
+		files:         g.constraintFiles,
+	}
+	keep := lock != nil && !update && isRequirementSatisfiedByLock(requirement, lock)
+
+	if requirement.Target.Type != gemapi.Version {
+		if keep {
+			m.candidates = []candidate{{hash: lock.Hash}}
+			return m, nil
+		}
+
+		solved, err := g.targetSolverFactory.New(repository).SolveContext(ctx, requirement.Target)
+		if err != nil {
+			return nil, err
+		}
+		m.candidates = []candidate{{hash: solved.Hash}}
+		return m, nil
+	}
+
+	target := requirement.Target
+	if update {
+		if target, err = updateTarget(requirement, lock); err != nil {
+			return nil, err
+		}
+	}
+
+	versions, err := repository.VersionsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	matching, err := matchingVersions(target.Version, versionsWithPrefix(versions, target.TagPrefix), target.Prereleases, target.Exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range matching {
+		c := candidate{version: &matching[i], hash: matching[i].Hash}
+		if keep && matching[i].Name == lock.Resolved.Version {
+			m.candidates = append([]candidate{c}, m.candidates...)
+			continue
+		}
+		m.candidates = append(m.candidates, c)
+	}
+	return m, nil
+}
+
+// lockedModule returns the module with the locked commit as its only candidate.
+func (g *gem) lockedModule(ctx context.Context, moduleKey gemapi.ModuleKey, requirement *gemapi.Requirement, lock *gemapi.Lock) (*constrainedModule, error) {
+	url := repositoryURL(moduleKey, requirement)
+	repository, err := g.registry.RepositoryContext(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	requirement = effectiveRequirement(moduleKey.Submodule, requirement)
+	c := candidate{hash: lock.Hash}
+	if requirement.Target.Type == gemapi.Version && lock.Resolved.Type == gemapi.Version {
+		prefix, version, err := parseVersionTag(lock.Resolved.Version)
+		if err != nil {
+			return nil, err
+		}
+		c.version = &RepositoryVersion{Name: lock.Resolved.Version, Prefix: prefix, Hash: lock.Hash, TagHash: lock.TagHash, Version: *version}
+	}
+	return &constrainedModule{
+		moduleKey:     moduleKey,
+		requirement:   requirement,
+		repositoryURL: url,
+		repository:    repository,
+		candidates:    []candidate{c},
+		constraints:   make(map[string][]constraint),
+		files:         g.constraintFiles,
+	}, nil
+}
+
+// solveConstraints chooses a candidate for every module such that the constraints declared by the chosen
+// versions and by the requirements are satisfied, preferring the candidates in order. Modules whose candidates
+// cannot be determined are left out, their error is reported once they are processed on their own. Constraints
+// on modules that are not required are ignored, the ones on Gardener only apply if its version is known. The
+// ones on modules not required by version cannot be verified and are logged as warnings.
+func (g *gem) solveConstraints(ctx context.Context, requirements *gemapi.Requirements, module func(moduleKey gemapi.ModuleKey, requirement *gemapi.Requirement) (*constrainedModule, error)) (map[gemapi.ModuleKey]*candidate, error) {
+	found := make([]*constrainedModule, len(requirements.Requirements))
+	_ = g.forEachModule(ctx, requirements, func(i int, moduleKey gemapi.ModuleKey, requirement *gemapi.Requirement) error {
+		m, err := module(moduleKey, requirement)
+		if err != nil {
+			withModuleKeyRequirementLogger(g.log, moduleKey, requirement).WithError(err).Debug("Leaving module out of constraint solving")
+			return nil
+		}
+		found[i] = m
+		if len(m.candidates) > 0 {
+			// The preferred candidates are checked in any case, so load their constraints concurrently.
+			// Errors are reported by the solver.
+			_, _ = m.declaredConstraints(ctx, m.candidates[0].hash)
+		}
+		return nil
+	})
+
+	var modules []*constrainedModule
+	for _, m := range found {
+		if m != nil {
+			modules = append(modules, m)
+		}
+	}
+
+	s, err := newConstraintSolver(modules, requirements.GardenerVersion)
+	if err != nil {
+		return nil, err
+	}
+	chosen, err := s.solve(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	unverifiable, err := s.unverifiableConstraints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, description := range unverifiable {
+		g.log.Warn(description)
+	}
+	return chosen, nil
+}
+
+// pinnedRequirement returns the given requirement restricted to the version chosen by the constraint solver.
+func pinnedRequirement(requirement *gemapi.Requirement, chosen *candidate) *gemapi.Requirement {
+	if chosen == nil || chosen.version == nil || requirement.Target.Type != gemapi.Version {
+		return requirement
+	}
+
+	out := *requirement
+	out.Target.Version = "=" + chosen.version.Version.String()
+	return &out
+}
@@ -0,0 +1,319 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/semver"
+	gemapi "github.com/gardener/gem/pkg/gem/api"
+)
+
+// fakeRepository is an in-memory repository whose files are keyed by hash and path.
+type fakeRepository struct {
+	files map[string]map[string]string
+	// hasFileCalls counts the HasFile calls per hash.
+	hasFileCalls map[string]int
+}
+
+func (r *fakeRepository) Revision(name string) (string, error) {
+	return r.RevisionContext(context.Background(), name)
+}
+
+func (r *fakeRepository) RevisionContext(ctx context.Context, name string) (string, error) {
+	return name, nil
+}
+
+func (r *fakeRepository) Branch(name string) (string, error) {
+	return r.BranchContext(context.Background(), name)
+}
+
+func (r *fakeRepository) BranchContext(ctx context.Context, name string) (string, error) {
+	return "", fmt.Errorf("branch %s not found", name)
+}
+
+func (r *fakeRepository) Versions() ([]RepositoryVersion, error) {
+	return r.VersionsContext(context.Background())
+}
+
+func (r *fakeRepository) VersionsContext(ctx context.Context) ([]RepositoryVersion, error) {
+	return nil, nil
+}
+
+func (r *fakeRepository) Latest() (string, error) {
+	return r.LatestContext(context.Background())
+}
+
+func (r *fakeRepository) LatestContext(ctx context.Context) (string, error) {
+	return "", fmt.Errorf("no commits")
+}
+
+func (r *fakeRepository) File(hash, path string) (io.Reader, error) {
+	return r.FileContext(context.Background(), hash, path)
+}
+
+func (r *fakeRepository) FileContext(ctx context.Context, hash, path string) (io.Reader, error) {
+	data, ok := r.files[hash][path]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return strings.NewReader(data), nil
+}
+
+func (r *fakeRepository) HasFile(hash, path string) (bool, error) {
+	return r.HasFileContext(context.Background(), hash, path)
+}
+
+func (r *fakeRepository) HasFileContext(ctx context.Context, hash, path string) (bool, error) {
+	if r.hasFileCalls == nil {
+		r.hasFileCalls = make(map[string]int)
+	}
+	r.hasFileCalls[hash]++
+	_, ok := r.files[hash][path]
+	return ok, nil
+}
+
+func (r *fakeRepository) Signature(hash string) (string, []byte, error) {
+	return r.SignatureContext(context.Background(), hash)
+}
+
+func (r *fakeRepository) SignatureContext(ctx context.Context, hash string) (string, []byte, error) {
+	return "", nil, nil
+}
+
+func (r *fakeRepository) ObjectInfo(hash string) (*ObjectInfo, error) {
+	return r.ObjectInfoContext(context.Background(), hash)
+}
+
+func (r *fakeRepository) ObjectInfoContext(ctx context.Context, hash string) (*ObjectInfo, error) {
+	return &ObjectInfo{}, nil
+}
+
+// fakeModule describes a module of a constraint solver test.
+type fakeModule struct {
+	repository string
+	// versions are the candidate versions, the preferred one first. An empty version is a candidate that is not
+	// a version, e.g. the commit of a branch.
+	versions []string
+	// constraints are the contents of the constraints files by version.
+	constraints map[string]string
+}
+
+func newFakeConstrainedModules(t *testing.T, modules []fakeModule) ([]*constrainedModule, map[string]*fakeRepository) {
+	var (
+		out          []*constrainedModule
+		repositories = make(map[string]*fakeRepository)
+		files        = newCallCache()
+	)
+	for _, fm := range modules {
+		repository := &fakeRepository{files: make(map[string]map[string]string)}
+		repositories[fm.repository] = repository
+
+		m := &constrainedModule{
+			moduleKey:     gemapi.ModuleKey{Repository: fm.repository},
+			requirement:   &gemapi.Requirement{Target: gemapi.Target{Type: gemapi.Version, Version: "*"}, Filename: "controller-registration.yaml"},
+			repositoryURL: fm.repository,
+			repository:    repository,
+			constraints:   make(map[string][]constraint),
+			files:         files,
+		}
+		for _, version := range fm.versions {
+			hash := fmt.Sprintf("%s@%s", fm.repository, version)
+			c := candidate{hash: hash}
+			if version != "" {
+				v, err := semver.NewVersion(version)
+				if err != nil {
+					t.Fatalf("invalid version %q: %v", version, err)
+				}
+				c.version = &RepositoryVersion{Name: version, Hash: hash, Version: *v}
+			} else {
+				m.requirement.Target = gemapi.Target{Type: gemapi.Branch, Branch: "master"}
+			}
+			m.candidates = append(m.candidates, c)
+
+			if data, ok := fm.constraints[version]; ok {
+				repository.files[hash] = map[string]string{DefaultConstraintsPath: "apiVersion: gem.gardener.cloud/v1alpha2\nkind: Constraints\n" + data}
+			}
+		}
+		out = append(out, m)
+	}
+	return out, repositories
+}
+
+func TestConstraintSolver(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		gardenerVersion string
+		modules         []fakeModule
+		want            map[string]string
+		conflicts       []string
+		unverifiable    []string
+		steps           int
+		// unread are candidates whose constraints files must not be read, by repository.
+		unread map[string][]string
+	}{
+		{
+			name: "greatest versions without constraints",
+			modules: []fakeModule{
+				{repository: "a", versions: []string{"2.0.0", "1.0.0"}},
+				{repository: "b", versions: []string{"1.5.0", "1.0.0"}},
+			},
+			want:  map[string]string{"a": "2.0.0", "b": "1.5.0"},
+			steps: 2,
+		},
+		{
+			name:            "backtracking to older versions",
+			gardenerVersion: "1.20.0",
+			modules: []fakeModule{
+				{repository: "a", versions: []string{"2.0.0", "1.1.0"}, constraints: map[string]string{
+					"2.0.0": "requires:\n- repository: b\n  version: \">=2.0.0\"\n",
+				}},
+				{repository: "b", versions: []string{"2.0.0", "1.5.0"}, constraints: map[string]string{
+					"2.0.0": "gardener: \">=1.30.0\"\n",
+				}},
+			},
+			want: map[string]string{"a": "1.1.0", "b": "1.5.0"},
+		},
+		{
+			name: "backjumping over an unrelated module",
+			modules: []fakeModule{
+				{repository: "a", versions: []string{"2.0.0", "1.0.0"}, constraints: map[string]string{
+					"2.0.0": "requires:\n- repository: c\n  version: \">=2.0.0\"\n",
+				}},
+				{repository: "b", versions: []string{"3.0.0", "2.0.0", "1.0.0"}},
+				{repository: "c", versions: []string{"1.0.0"}},
+			},
+			want:   map[string]string{"a": "1.0.0", "b": "3.0.0", "c": "1.0.0"},
+			steps:  6,
+			unread: map[string][]string{"b": {"2.0.0", "1.0.0"}},
+		},
+		{
+			name: "constraints of later modules",
+			modules: []fakeModule{
+				{repository: "a", versions: []string{"2.0.0", "1.0.0"}},
+				{repository: "b", versions: []string{"1.0.0"}, constraints: map[string]string{
+					"1.0.0": "requires:\n- repository: a\n  version: <2.0.0\n",
+				}},
+			},
+			want: map[string]string{"a": "1.0.0", "b": "1.0.0"},
+		},
+		{
+			name:            "conflict",
+			gardenerVersion: "1.20.0",
+			modules: []fakeModule{
+				{repository: "a", versions: []string{"2.0.0"}, constraints: map[string]string{
+					"2.0.0": "requires:\n- repository: b\n  version: \">=2.0.0\"\n",
+				}},
+				{repository: "b", versions: []string{"2.0.0", "1.0.0"}, constraints: map[string]string{
+					"2.0.0": "gardener: \">=1.30.0\"\n",
+				}},
+			},
+			conflicts: []string{
+				"a 2.0.0 requires b >=2.0.0, candidates are 2.0.0, 1.0.0",
+				"b 2.0.0 requires Gardener >=1.30.0, but Gardener is 1.20.0",
+			},
+		},
+		{
+			name: "constraints on modules not required by version",
+			modules: []fakeModule{
+				{repository: "a", versions: []string{"1.0.0"}, constraints: map[string]string{
+					"1.0.0": "requires:\n- repository: b\n  version: \">=2.0.0\"\n- repository: c\n  version: \">=1.0.0\"\n",
+				}},
+				{repository: "b", versions: []string{""}},
+			},
+			want:         map[string]string{"a": "1.0.0", "b": ""},
+			unverifiable: []string{"a 1.0.0 requires b >=2.0.0, which cannot be verified since it is not required by version"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			modules, repositories := newFakeConstrainedModules(t, tc.modules)
+			s, err := newConstraintSolver(modules, tc.gardenerVersion)
+			if err != nil {
+				t.Fatalf("newConstraintSolver: %v", err)
+			}
+
+			chosen, err := s.solve(ctx)
+			if tc.conflicts != nil {
+				conflictErr := &ConstraintConflictError{}
+				if !errors.As(err, &conflictErr) {
+					t.Fatalf("solve returned %v, want a ConstraintConflictError", err)
+				}
+				if !reflect.DeepEqual(conflictErr.Conflicts, tc.conflicts) {
+					t.Fatalf("conflicts are\n%s\nwant\n%s", strings.Join(conflictErr.Conflicts, "\n"), strings.Join(tc.conflicts, "\n"))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("solve: %v", err)
+			}
+
+			got := make(map[string]string, len(chosen))
+			for moduleKey, c := range chosen {
+				got[moduleKey.Repository] = strings.TrimPrefix(c.hash, moduleKey.Repository+"@")
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("chosen versions are %v, want %v", got, tc.want)
+			}
+			if tc.steps != 0 && s.steps != tc.steps {
+				t.Fatalf("solver took %d steps, want %d", s.steps, tc.steps)
+			}
+			for repository, versions := range tc.unread {
+				for _, version := range versions {
+					if n := repositories[repository].hasFileCalls[repository+"@"+version]; n != 0 {
+						t.Fatalf("constraints of %s %s were read %d times, want none", repository, version, n)
+					}
+				}
+			}
+
+			unverifiable, err := s.unverifiableConstraints(ctx)
+			if err != nil {
+				t.Fatalf("unverifiableConstraints: %v", err)
+			}
+			if !reflect.DeepEqual(unverifiable, tc.unverifiable) {
+				t.Fatalf("unverifiable constraints are %v, want %v", unverifiable, tc.unverifiable)
+			}
+		})
+	}
+}
+
+func TestConstrainedModuleReadsConstraintsOncePerHash(t *testing.T) {
+	modules, repositories := newFakeConstrainedModules(t, []fakeModule{
+		{repository: "a", versions: []string{"1.0.0"}, constraints: map[string]string{"1.0.0": "gardener: \">=1.0.0\"\n"}},
+	})
+	// A second module of the same repository at the same commit, e.g. of a later run, shares the cache.
+	other := *modules[0]
+	other.constraints = make(map[string][]constraint)
+
+	for _, m := range []*constrainedModule{modules[0], modules[0], &other} {
+		constraints, err := m.constraintsAt(context.Background(), &m.candidates[0])
+		if err != nil {
+			t.Fatalf("constraintsAt: %v", err)
+		}
+		if len(constraints) != 1 || constraints[0].versionRange != ">=1.0.0" {
+			t.Fatalf("constraints are %v, want the Gardener constraint", constraints)
+		}
+	}
+	if n := repositories["a"].hasFileCalls["a@1.0.0"]; n != 1 {
+		t.Fatalf("constraints file was read %d times, want once", n)
+	}
+}
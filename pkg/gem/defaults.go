@@ -14,15 +14,49 @@
 
 package gem
 
-import "github.com/sirupsen/logrus"
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+)
 
 const (
 	DefaultPath = "controller-registration.yaml"
+	// DefaultConstraintsPath is the name of the file next to the registration file in which a module declares
+	// the versions of other modules and of Gardener it works with.
+	DefaultConstraintsPath = "gem-constraints.yaml"
+
+	// DefaultJobs is the default maximum number of modules that are processed concurrently.
+	DefaultJobs = 4
+
+	digestPrefix = "sha256:"
 )
 
 var (
 	DefaultLogger                  = logrus.New()
-	DefaultRegistry                = NewRepositoryRegistryCache(NewRepositoryRegistryCachingRepositoryWrapper(GitRepositoryRegistry))
+	DefaultAuthProvider            = NewCredentialsAuthProvider(nil)
+	DefaultGitCache                = NewGitCache(DefaultCacheDir(), DefaultAuthProvider)
+	DefaultRegistry                = NewRepositoryRegistryCache(NewRepositoryRegistryCachingRepositoryWrapper(NewRemoteRepositoryRegistry(DefaultAuthProvider, DefaultGitCache)))
 	DefaultTargetSolverFactoryFunc = TargetSolverFactoryFunc(NewSolver)
 	Default                        = New(DefaultLogger, DefaultRegistry, DefaultTargetSolverFactoryFunc)
 )
+
+// DefaultCacheDir returns the default directory of the git cache. It honors $XDG_CACHE_HOME
+// and falls back to an in-memory cache if no user cache directory can be determined.
+func DefaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "gem")
+}
+
+// DefaultCredentialsFile returns the default location of the credentials file. It honors $XDG_CONFIG_HOME.
+func DefaultCredentialsFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "gem", "credentials.yaml")
+}
@@ -0,0 +1,200 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gem
+
+import (
+	"bytes"
+	"fmt"
+
+	gemapi "github.com/gardener/gem/pkg/gem/api"
+	gemapilatest "github.com/gardener/gem/pkg/gem/api/latest"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const requirementsKey = "requirements"
+
+// requirementsDocument is a requirements file whose comments and key order are kept when it is edited.
+type requirementsDocument struct {
+	root *yaml.Node
+	gv   schema.GroupVersion
+}
+
+func parseRequirementsDocument(data []byte) (*requirementsDocument, error) {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) != 1 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("requirements are not a YAML mapping")
+	}
+
+	apiVersion := mappingValue(doc.Content[0], "apiVersion")
+	if apiVersion == nil {
+		return nil, fmt.Errorf("requirements do not specify an apiVersion")
+	}
+	gv, err := schema.ParseGroupVersion(apiVersion.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &requirementsDocument{root: doc, gv: gv}, nil
+}
+
+// mappingValue returns the value of the given key of the mapping node or nil if there is none.
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// requirements returns the sequence node of the requirements, creating it if create is set.
+func (d *requirementsDocument) requirements(create bool) (*yaml.Node, error) {
+	mapping := d.root.Content[0]
+	if requirements := mappingValue(mapping, requirementsKey); requirements != nil {
+		if requirements.Kind != yaml.SequenceNode {
+			return nil, fmt.Errorf("%s are not a YAML sequence", requirementsKey)
+		}
+		return requirements, nil
+	}
+	if !create {
+		return nil, nil
+	}
+
+	requirements := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: requirementsKey}, requirements)
+	return requirements, nil
+}
+
+// moduleKey decodes the module key of the given node of the requirements sequence in the version of the document.
+func (d *requirementsDocument) moduleKey(item *yaml.Node) (gemapi.ModuleKey, error) {
+	data, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion":    d.gv.String(),
+		"kind":          "Requirements",
+		requirementsKey: []*yaml.Node{item},
+	})
+	if err != nil {
+		return gemapi.ModuleKey{}, err
+	}
+
+	requirements, err := LoadRequirements(data)
+	if err != nil {
+		return gemapi.ModuleKey{}, err
+	}
+	for moduleKey := range requirements.Requirements {
+		return moduleKey, nil
+	}
+	return gemapi.ModuleKey{}, fmt.Errorf("requirement at line %d is empty", item.Line)
+}
+
+// encodeRequirement encodes the requirement of the module as node of the requirements sequence in the version
+// of the document.
+func (d *requirementsDocument) encodeRequirement(moduleKey gemapi.ModuleKey, requirement *gemapi.Requirement) (*yaml.Node, error) {
+	data, err := runtime.Encode(gemapilatest.NewCodec(d.gv), &gemapi.Requirements{
+		Requirements: map[gemapi.ModuleKey]*gemapi.Requirement{moduleKey: requirement},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := parseRequirementsDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	requirements, err := encoded.requirements(false)
+	if err != nil {
+		return nil, err
+	}
+	if requirements == nil || len(requirements.Content) != 1 {
+		return nil, fmt.Errorf("could not encode requirement for %s", &moduleKey)
+	}
+	return requirements.Content[0], nil
+}
+
+func (d *requirementsDocument) bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(d.root); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// AddRequirement appends the requirement of the module to the requirements of the given requirements file,
+// keeping its comments and key order. The requirement is written in the API version of the file. It is an error
+// if the module is already required.
+func AddRequirement(data []byte, moduleKey gemapi.ModuleKey, requirement *gemapi.Requirement) ([]byte, error) {
+	existing, err := LoadRequirements(data)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := existing.Requirements[moduleKey]; ok {
+		return nil, fmt.Errorf("%s is already required", &moduleKey)
+	}
+
+	doc, err := parseRequirementsDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := doc.encodeRequirement(moduleKey, requirement)
+	if err != nil {
+		return nil, err
+	}
+
+	requirements, err := doc.requirements(true)
+	if err != nil {
+		return nil, err
+	}
+	requirements.Content = append(requirements.Content, item)
+	return doc.bytes()
+}
+
+// RemoveRequirement removes the requirement of the module from the requirements of the given requirements file,
+// keeping the comments and key order of everything else. Requirements of profiles are not touched. It is an error
+// if the module is not required.
+func RemoveRequirement(data []byte, moduleKey gemapi.ModuleKey) ([]byte, error) {
+	doc, err := parseRequirementsDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
+	requirements, err := doc.requirements(false)
+	if err != nil {
+		return nil, err
+	}
+	if requirements != nil {
+		for i, item := range requirements.Content {
+			itemModuleKey, err := doc.moduleKey(item)
+			if err != nil {
+				return nil, err
+			}
+
+			if itemModuleKey == moduleKey {
+				requirements.Content = append(requirements.Content[:i], requirements.Content[i+1:]...)
+				return doc.bytes()
+			}
+		}
+	}
+	return nil, fmt.Errorf("%s is not required", &moduleKey)
+}
@@ -0,0 +1,188 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gem
+
+import (
+	"testing"
+
+	gemapi "github.com/gardener/gem/pkg/gem/api"
+)
+
+func TestAddRequirement(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		data        string
+		moduleKey   gemapi.ModuleKey
+		requirement *gemapi.Requirement
+		want        string
+		err         string
+	}{
+		{
+			name: "keeps comments and key order",
+			data: `# Extensions of the landscape.
+kind: Requirements
+apiVersion: gem.gardener.cloud/v1alpha2
+requirements:
+# Infrastructure
+- repository: github.com/gardener/gardener-extension-provider-aws
+  version: ^1.20.0 # pinned until 1.21 is fixed
+`,
+			moduleKey:   gemapi.ModuleKey{Repository: "github.com/gardener/gardener-extension-networking-calico"},
+			requirement: &gemapi.Requirement{Target: gemapi.Target{Type: gemapi.Version, Version: "^1.10.0"}, Filename: "controller-registration.yaml"},
+			want: `# Extensions of the landscape.
+kind: Requirements
+apiVersion: gem.gardener.cloud/v1alpha2
+requirements:
+  # Infrastructure
+  - repository: github.com/gardener/gardener-extension-provider-aws
+    version: ^1.20.0 # pinned until 1.21 is fixed
+  - repository: github.com/gardener/gardener-extension-networking-calico
+    version: ^1.10.0
+`,
+		},
+		{
+			name: "creates the requirements",
+			data: `# No requirements yet.
+apiVersion: gem.gardener.cloud/v1alpha2
+kind: Requirements
+`,
+			moduleKey:   gemapi.ModuleKey{Repository: "gitlab.com/group/subgroup/project", Submodule: "provider-aws"},
+			requirement: &gemapi.Requirement{Target: gemapi.Target{Type: gemapi.Latest}, Filename: "controller-registration.yaml"},
+			want: `# No requirements yet.
+apiVersion: gem.gardener.cloud/v1alpha2
+kind: Requirements
+requirements:
+  - repository: gitlab.com/group/subgroup/project
+    submodule: provider-aws
+`,
+		},
+		{
+			name: "in the version of the file",
+			data: `apiVersion: gem.gardener.cloud/v1alpha1
+kind: Requirements
+requirements:
+- name: github.com/gardener/gardener-extension-provider-aws
+  version: ^1.20.0
+`,
+			moduleKey:   gemapi.ModuleKey{Repository: "github.com/gardener/gardener-extensions", Submodule: "controllers/os-ubuntu"},
+			requirement: &gemapi.Requirement{Target: gemapi.Target{Type: gemapi.Branch, Branch: "master"}, Filename: "controller-registration.yaml"},
+			want: `apiVersion: gem.gardener.cloud/v1alpha1
+kind: Requirements
+requirements:
+  - name: github.com/gardener/gardener-extension-provider-aws
+    version: ^1.20.0
+  - branch: master
+    name: github.com/gardener/gardener-extensions/controllers/os-ubuntu
+`,
+		},
+		{
+			name: "already required",
+			data: `apiVersion: gem.gardener.cloud/v1alpha2
+kind: Requirements
+requirements:
+- repository: github.com/gardener/gardener-extension-provider-aws
+  version: ^1.20.0
+`,
+			moduleKey:   gemapi.ModuleKey{Repository: "github.com/gardener/gardener-extension-provider-aws"},
+			requirement: &gemapi.Requirement{Target: gemapi.Target{Type: gemapi.Latest}, Filename: "controller-registration.yaml"},
+			err:         "github.com/gardener/gardener-extension-provider-aws is already required",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := AddRequirement([]byte(tc.data), tc.moduleKey, tc.requirement)
+			checkEdit(t, got, err, tc.want, tc.err)
+		})
+	}
+}
+
+func TestRemoveRequirement(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		data      string
+		moduleKey gemapi.ModuleKey
+		want      string
+		err       string
+	}{
+		{
+			name: "keeps comments of other requirements",
+			data: `apiVersion: gem.gardener.cloud/v1alpha2
+kind: Requirements
+requirements:
+# Infrastructure
+- repository: github.com/gardener/gardener-extension-provider-aws
+  version: ^1.20.0 # pinned until 1.21 is fixed
+- source: gitlab.com/group/subgroup/project//networking-calico
+  version: ^1.10.0
+# Profiles are not touched.
+profiles:
+- name: live
+  requirements:
+  - source: gitlab.com/group/subgroup/project//networking-calico
+    version: 1.10.0
+`,
+			moduleKey: gemapi.ModuleKey{Repository: "gitlab.com/group/subgroup/project", Submodule: "networking-calico"},
+			want: `apiVersion: gem.gardener.cloud/v1alpha2
+kind: Requirements
+requirements:
+  # Infrastructure
+  - repository: github.com/gardener/gardener-extension-provider-aws
+    version: ^1.20.0 # pinned until 1.21 is fixed
+# Profiles are not touched.
+profiles:
+  - name: live
+    requirements:
+      - source: gitlab.com/group/subgroup/project//networking-calico
+        version: 1.10.0
+`,
+		},
+		{
+			name: "not required",
+			data: `apiVersion: gem.gardener.cloud/v1alpha2
+kind: Requirements
+requirements:
+- repository: github.com/gardener/gardener-extension-provider-aws
+  version: ^1.20.0
+`,
+			moduleKey: gemapi.ModuleKey{Repository: "github.com/gardener/gardener-extension-provider-azure"},
+			err:       "github.com/gardener/gardener-extension-provider-azure is not required",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := RemoveRequirement([]byte(tc.data), tc.moduleKey)
+			checkEdit(t, got, err, tc.want, tc.err)
+		})
+	}
+}
+
+func checkEdit(t *testing.T, got []byte, err error, want, wantErr string) {
+	t.Helper()
+	if wantErr != "" {
+		if err == nil || err.Error() != wantErr {
+			t.Fatalf("returned %v, want %s", err, wantErr)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Fatalf("edited file is\n%s\nwant\n%s", got, want)
+	}
+
+	// The edited file has to stay loadable.
+	if _, err := LoadRequirements(got); err != nil {
+		t.Fatalf("edited file cannot be loaded: %v", err)
+	}
+}
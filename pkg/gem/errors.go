@@ -0,0 +1,179 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gem
+
+import (
+	"fmt"
+	"strings"
+
+	gemapi "github.com/gardener/gem/pkg/gem/api"
+)
+
+// ModuleError is the error of processing a single module.
+type ModuleError struct {
+	ModuleKey   gemapi.ModuleKey
+	Requirement *gemapi.Requirement
+	Err         error
+}
+
+func (e *ModuleError) Error() string {
+	return fmt.Sprintf("extension %q (requirement %q): %v", &e.ModuleKey, &e.Requirement.Target, e.Err)
+}
+
+func (e *ModuleError) Unwrap() error {
+	return e.Err
+}
+
+// MissingFileError is returned if the file of a requirement does not exist at the locked hash.
+type MissingFileError struct {
+	Lock *gemapi.Lock
+	Path string
+}
+
+func (e *MissingFileError) Error() string {
+	return fmt.Sprintf("lock %v does not have file %s", e.Lock, e.Path)
+}
+
+// DigestMismatchError is returned if the content of a file does not match the digest recorded in the lock.
+type DigestMismatchError struct {
+	Lock *gemapi.Lock
+	Path string
+	// Digest is the actual digest of the file.
+	Digest string
+}
+
+func (e *DigestMismatchError) Error() string {
+	return fmt.Sprintf("file %s of lock %v has digest %s but %s is recorded", e.Path, e.Lock, e.Digest, e.Lock.Digest)
+}
+
+// ValidationError is returned if an object of a controller registration file is not valid.
+type ValidationError struct {
+	Kind string
+	Name string
+	Err  error
+}
+
+func (e *ValidationError) Error() string {
+	kind := e.Kind
+	if kind == "" {
+		kind = "object"
+	}
+	if e.Name == "" {
+		return fmt.Sprintf("invalid %s: %v", kind, e.Err)
+	}
+	return fmt.Sprintf("invalid %s %q: %v", kind, e.Name, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// SignatureError is returned if the resolved tag or commit of a lock is not signed by a trusted signer.
+type SignatureError struct {
+	Lock *gemapi.Lock
+	// Err is the reason why the signatures were rejected. It is nil if neither the tag nor the commit is signed.
+	Err error
+}
+
+func (e *SignatureError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("lock %v is not signed", e.Lock)
+	}
+	return fmt.Sprintf("lock %v is not signed by a trusted signer: %v", e.Lock, e.Err)
+}
+
+func (e *SignatureError) Unwrap() error {
+	return e.Err
+}
+
+// ResourceConflictError is returned if resources are registered as primary by more than one extension.
+type ResourceConflictError struct {
+	Conflicts []ResourceConflict
+}
+
+func formatResourceConflict(conflict *ResourceConflict) string {
+	names := make([]string, 0, len(conflict.ModuleKeys))
+	for _, key := range conflict.ModuleKeys {
+		names = append(names, fmt.Sprintf("%q", &key))
+	}
+	return fmt.Sprintf("%v is registered as primary by extensions %s", &conflict.Resource, strings.Join(names, ", "))
+}
+
+func (e *ResourceConflictError) Error() string {
+	if len(e.Conflicts) == 1 {
+		return fmt.Sprintf("resource %s", formatResourceConflict(&e.Conflicts[0]))
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d resources are registered as primary by more than one extension:", len(e.Conflicts))
+	for i := range e.Conflicts {
+		sb.WriteString("\n* ")
+		sb.WriteString(formatResourceConflict(&e.Conflicts[i]))
+	}
+	return sb.String()
+}
+
+// maxConstraintConflicts limits the number of conflicts a ConstraintConflictError lists.
+const maxConstraintConflicts = 20
+
+// ConstraintConflictError is returned if no combination of versions satisfies the constraints of all modules.
+type ConstraintConflictError struct {
+	// Conflicts describe the constraints that ruled out versions, sorted.
+	Conflicts []string
+}
+
+func (e *ConstraintConflictError) Error() string {
+	if len(e.Conflicts) == 1 {
+		return fmt.Sprintf("no combination of versions satisfies all constraints: %s", e.Conflicts[0])
+	}
+
+	var sb strings.Builder
+	sb.WriteString("no combination of versions satisfies all constraints:")
+	for i, conflict := range e.Conflicts {
+		if i == maxConstraintConflicts {
+			fmt.Fprintf(&sb, "\n* and %d more", len(e.Conflicts)-i)
+			break
+		}
+		sb.WriteString("\n* ")
+		sb.WriteString(conflict)
+	}
+	return sb.String()
+}
+
+// ModuleErrors are the errors of all modules that failed, sorted by module name.
+type ModuleErrors []*ModuleError
+
+func (e ModuleErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d extensions failed:", len(e))
+	for _, err := range e {
+		sb.WriteString("\n* ")
+		sb.WriteString(err.Error())
+	}
+	return sb.String()
+}
+
+// ModuleKeys returns the keys of all failed modules.
+func (e ModuleErrors) ModuleKeys() []gemapi.ModuleKey {
+	out := make([]gemapi.ModuleKey, 0, len(e))
+	for _, err := range e {
+		out = append(out, err.ModuleKey)
+	}
+	return out
+}
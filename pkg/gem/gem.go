@@ -15,9 +15,19 @@
 package gem
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io/ioutil"
 	"k8s.io/apimachinery/pkg/runtime"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/Masterminds/semver"
 
@@ -26,6 +36,8 @@ import (
 	"github.com/pkg/errors"
 
 	gemapi "github.com/gardener/gem/pkg/gem/api"
+	gemapilatest "github.com/gardener/gem/pkg/gem/api/latest"
+	"github.com/gardener/gem/pkg/version"
 )
 
 func optSubmodulePath(submodule, filename string) string {
@@ -85,46 +97,333 @@ func UpdateModuleKeySet(set ModuleKeySet) UpdatePolicy {
 	return updateModuleKeySet(set)
 }
 
+type updateStrategyPolicy struct {
+	UpdatePolicy
+	strategy gemapi.UpdateStrategy
+}
+
+func (u *updateStrategyPolicy) UpdateStrategy(key gemapi.ModuleKey) gemapi.UpdateStrategy {
+	return u.strategy
+}
+
+// WithUpdateStrategy returns an UpdatePolicy that updates the modules of the given policy with the given strategy,
+// regardless of the update strategies of their requirements.
+func WithUpdateStrategy(policy UpdatePolicy, strategy gemapi.UpdateStrategy) UpdatePolicy {
+	return &updateStrategyPolicy{policy, strategy}
+}
+
+// updateStrategy returns the update strategy of the module, preferring the one of the policy over the one of the requirement.
+func updateStrategy(policy UpdatePolicy, moduleKey gemapi.ModuleKey, requirement *gemapi.Requirement) gemapi.UpdateStrategy {
+	if strategyPolicy, ok := policy.(UpdateStrategyPolicy); ok {
+		if strategy := strategyPolicy.UpdateStrategy(moduleKey); strategy != "" {
+			return strategy
+		}
+	}
+	if requirement.UpdateStrategy != "" {
+		return requirement.UpdateStrategy
+	}
+	return gemapi.UpdateStrategyLatestInRange
+}
+
 type repositoryInterface struct {
+	log          logrus.FieldLogger
 	targetSolver TargetSolver
 	repository   Repository
+	verifier     SignatureVerifier
 }
 
 func NewRepositoryInterface(targetSolver TargetSolver, repository Repository) RepositoryInterface {
-	return &repositoryInterface{targetSolver, repository}
+	return &repositoryInterface{log: DefaultLogger, targetSolver: targetSolver, repository: repository}
 }
 
 func (r *repositoryInterface) SolveTarget(target gemapi.Target) (*gemapi.Lock, error) {
-	return r.targetSolver.Solve(target)
+	return r.SolveTargetContext(context.Background(), target)
 }
 
-func (r *repositoryInterface) Verify(submodule string, requirement *gemapi.Requirement, lock *gemapi.Lock) error {
-	path := optSubmodulePath(submodule, requirement.Filename)
-	ok, err := r.repository.HasFile(lock.Hash, path)
+func (r *repositoryInterface) SolveTargetContext(ctx context.Context, target gemapi.Target) (*gemapi.Lock, error) {
+	return r.targetSolver.SolveContext(ctx, target)
+}
+
+// lockedVersion returns the current version of the tag the given lock was resolved from.
+func (r *repositoryInterface) lockedVersion(ctx context.Context, lock *gemapi.Lock) (*RepositoryVersion, error) {
+	versions, err := r.repository.VersionsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, version := range versions {
+		if version.Name == lock.Resolved.Version {
+			v := version
+			return &v, nil
+		}
+	}
+	return nil, fmt.Errorf("tag %s of lock %v does not exist anymore", lock.Resolved.Version, lock)
+}
+
+// isLegacyTagLock checks whether the lock recorded the hash of an annotated tag object instead of the commit hash.
+func isLegacyTagLock(lock *gemapi.Lock, version *RepositoryVersion) bool {
+	return lock.TagHash == "" && version.TagHash != "" && lock.Hash == version.TagHash
+}
+
+// verifyTag checks that the tag a lock was resolved from has neither been re-pointed nor re-created.
+func (r *repositoryInterface) verifyTag(ctx context.Context, lock *gemapi.Lock) error {
+	if lock.Resolved.Type != gemapi.Version {
+		return nil
+	}
+
+	version, err := r.lockedVersion(ctx, lock)
 	if err != nil {
 		return err
 	}
+
+	if isLegacyTagLock(lock, version) {
+		return nil
+	}
+	if version.Hash != lock.Hash || version.TagHash != lock.TagHash {
+		return fmt.Errorf("tag %s of lock %v has changed: now points to commit %s (tag object %q)", version.Name, lock, version.Hash, version.TagHash)
+	}
+	return nil
+}
+
+// digest returns the digest of the given data in the format recorded in locks.
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return digestPrefix + hex.EncodeToString(sum[:])
+}
+
+// verifyDigest checks that the data of the file at the given path matches the digest of the lock, if recorded.
+func verifyDigest(lock *gemapi.Lock, path string, data []byte) error {
+	if lock.Digest == "" {
+		return nil
+	}
+	if !strings.HasPrefix(lock.Digest, digestPrefix) {
+		return fmt.Errorf("unsupported digest %q of lock %v", lock.Digest, lock)
+	}
+
+	if actual := digest(data); actual != lock.Digest {
+		return &DigestMismatchError{Lock: lock, Path: path, Digest: actual}
+	}
+	return nil
+}
+
+// RequirementsDigest returns the digest of the serialized requirements and, if versions are denied, of the
+// serialized deny list, e.g. `sha256:<hex>`.
+func RequirementsDigest(requirements *gemapi.Requirements, denyList *gemapi.DenyList) (string, error) {
+	data, err := WriteRequirements(requirements)
+	if err != nil {
+		return "", err
+	}
+	if denyList != nil && len(denyList.Denied) > 0 {
+		denied, err := runtime.Encode(gemapilatest.Codec, denyList)
+		if err != nil {
+			return "", err
+		}
+		data = append(data, denied...)
+	}
+	return digest(data), nil
+}
+
+// RequirementsChanged returns whether the requirements or the deny list differ from the ones the locks were
+// resolved from. Locks without a recorded requirements digest are considered changed.
+func RequirementsChanged(requirements *gemapi.Requirements, denyList *gemapi.DenyList, locks *gemapi.Locks) (bool, error) {
+	if locks == nil || locks.Header.RequirementsDigest == "" {
+		return true, nil
+	}
+
+	requirementsDigest, err := RequirementsDigest(requirements, denyList)
+	if err != nil {
+		return false, err
+	}
+	return requirementsDigest != locks.Header.RequirementsDigest, nil
+}
+
+// locksHeader returns the header of locks resolved from the given requirements.
+func (g *gem) locksHeader(requirements *gemapi.Requirements) (gemapi.LocksHeader, error) {
+	requirementsDigest, err := RequirementsDigest(requirements, g.denyList)
+	if err != nil {
+		return gemapi.LocksHeader{}, err
+	}
+	return gemapi.LocksHeader{RequirementsDigest: requirementsDigest, GemVersion: version.Get()}, nil
+}
+
+// readFile reads the registration file of the requirement at the hash of the lock.
+func (r *repositoryInterface) readFile(ctx context.Context, submodule string, requirement *gemapi.Requirement, lock *gemapi.Lock) (string, []byte, error) {
+	path := optSubmodulePath(submodule, requirement.Filename)
+	reader, err := r.repository.FileContext(ctx, lock.Hash, path)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "error getting file with hash %s at %s", lock.Hash, path)
+	}
+
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "error reading file with hash %s at %s", lock.Hash, path)
+	}
+	return path, data, nil
+}
+
+// verifySignature verifies the signature of the annotated tag of the lock or, if the tag is not signed
+// by a trusted signer, the one of the commit. It returns the identity of the signer.
+func (r *repositoryInterface) verifySignature(ctx context.Context, lock *gemapi.Lock) (string, error) {
+	hashes := []string{lock.Hash}
+	if lock.TagHash != "" {
+		hashes = []string{lock.TagHash, lock.Hash}
+	}
+
+	var errs []string
+	for _, hash := range hashes {
+		signature, payload, err := r.repository.SignatureContext(ctx, hash)
+		if err != nil {
+			return "", err
+		}
+		if signature == "" {
+			continue
+		}
+
+		signer, err := r.verifier.Verify(signature, payload)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", hash, err))
+			continue
+		}
+		return signer, nil
+	}
+
+	if len(errs) == 0 {
+		return "", &SignatureError{Lock: lock}
+	}
+	return "", &SignatureError{Lock: lock, Err: fmt.Errorf("%s", strings.Join(errs, ", "))}
+}
+
+// verify verifies the lock like Verify and returns the identity of the signer if signatures are verified.
+func (r *repositoryInterface) verify(ctx context.Context, submodule string, requirement *gemapi.Requirement, lock *gemapi.Lock) (string, error) {
+	if err := r.verifyTag(ctx, lock); err != nil {
+		return "", err
+	}
+
+	path := optSubmodulePath(submodule, requirement.Filename)
+	ok, err := r.repository.HasFileContext(ctx, lock.Hash, path)
+	if err != nil {
+		return "", err
+	}
 	if !ok {
-		return fmt.Errorf("lock %v does not have file %s", lock, path)
+		return "", &MissingFileError{Lock: lock, Path: path}
+	}
+
+	if lock.Digest != "" {
+		_, data, err := r.readFile(ctx, submodule, requirement, lock)
+		if err != nil {
+			return "", err
+		}
+		if err := verifyDigest(lock, path, data); err != nil {
+			return "", err
+		}
+	}
+
+	if r.verifier == nil {
+		return "", nil
+	}
+	signer, err := r.verifySignature(ctx, lock)
+	if err != nil {
+		return "", err
+	}
+	if lock.Signer != "" && lock.Signer != signer {
+		return "", &SignatureError{Lock: lock, Err: fmt.Errorf("signed by %s instead of %s", signer, lock.Signer)}
+	}
+	return signer, nil
+}
+
+func (r *repositoryInterface) Verify(submodule string, requirement *gemapi.Requirement, lock *gemapi.Lock) error {
+	return r.VerifyContext(context.Background(), submodule, requirement, lock)
+}
+
+func (r *repositoryInterface) VerifyContext(ctx context.Context, submodule string, requirement *gemapi.Requirement, lock *gemapi.Lock) error {
+	_, err := r.verify(ctx, submodule, requirement, lock)
+	return err
+}
+
+// recordDigest records the digest of the registration file in the lock unless it already has one.
+func (r *repositoryInterface) recordDigest(ctx context.Context, submodule string, requirement *gemapi.Requirement, lock *gemapi.Lock) error {
+	if lock.Digest != "" {
+		return nil
+	}
+
+	_, data, err := r.readFile(ctx, submodule, requirement, lock)
+	if err != nil {
+		return err
+	}
+	lock.Digest = digest(data)
+	return nil
+}
+
+// recordMetadata records the time and subject of the resolved commit or tag in the lock unless it already has them.
+// If the lock was resolved anew, the resolution time and the version of gem are recorded as well.
+func (r *repositoryInterface) recordMetadata(ctx context.Context, lock *gemapi.Lock, resolved bool) error {
+	if lock.CommitTime.IsZero() || lock.Subject == "" {
+		info, err := r.repository.ObjectInfoContext(ctx, lock.Hash)
+		if err != nil {
+			return err
+		}
+		lock.CommitTime, lock.Subject = info.Time, info.Subject
+
+		if lock.TagHash != "" {
+			tagInfo, err := r.repository.ObjectInfoContext(ctx, lock.TagHash)
+			if err != nil {
+				return err
+			}
+			lock.Subject = tagInfo.Subject
+		}
+	}
+
+	if resolved {
+		lock.ResolvedAt = time.Now().UTC().Truncate(time.Second)
+		lock.GemVersion = version.Get()
 	}
 	return nil
 }
 
+// effectiveRequirement returns the requirement with the tag prefix derived from the submodule, if requested.
+func effectiveRequirement(submodule string, requirement *gemapi.Requirement) *gemapi.Requirement {
+	if !requirement.SubmoduleTagPrefix || requirement.Target.Type != gemapi.Version || submodule == "" {
+		return requirement
+	}
+
+	out := *requirement
+	out.Target.TagPrefix = strings.Trim(submodule, "/") + "/"
+	return &out
+}
+
 func (r *repositoryInterface) Solve(submodule string, requirement *gemapi.Requirement) (*gemapi.Lock, error) {
-	lock, err := r.SolveTarget(requirement.Target)
+	return r.SolveContext(context.Background(), submodule, requirement)
+}
+
+func (r *repositoryInterface) SolveContext(ctx context.Context, submodule string, requirement *gemapi.Requirement) (*gemapi.Lock, error) {
+	requirement = effectiveRequirement(submodule, requirement)
+	lock, err := r.SolveTargetContext(ctx, requirement.Target)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := r.Verify(submodule, requirement, lock); err != nil {
+	signer, err := r.verify(ctx, submodule, requirement, lock)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.recordDigest(ctx, submodule, requirement, lock); err != nil {
 		return nil, err
 	}
+	if err := r.recordMetadata(ctx, lock, true); err != nil {
+		return nil, err
+	}
+	if signer != "" {
+		lock.Signer = signer
+	}
 	return lock, nil
 }
 
 func isRequirementSatisfiedByLock(requirement *gemapi.Requirement, lock *gemapi.Lock) bool {
 	if requirement.Target.Type != gemapi.Version || lock.Resolved.Type != gemapi.Version {
-		return requirement.Target == lock.Target
+		return requirement.Target.Equal(&lock.Target)
+	}
+	if excludedLock(requirement, lock) != nil {
+		return false
 	}
 
 	newRange, err := semver.NewConstraint(requirement.Target.Version)
@@ -132,57 +431,271 @@ func isRequirementSatisfiedByLock(requirement *gemapi.Requirement, lock *gemapi.
 		return false
 	}
 
-	oldVersion, err := semver.NewVersion(lock.Resolved.Version)
-	if err != nil {
+	prefix, oldVersion, err := parseVersionTag(lock.Resolved.Version)
+	if err != nil || prefix != requirement.Target.TagPrefix {
 		return false
 	}
 
-	return newRange.Check(oldVersion)
+	return matchesVersion(newRange, oldVersion, requirement.Target.Prereleases)
+}
+
+// excludedLock returns the exclusion of the version the given lock was resolved from by the given requirement,
+// if it is excluded.
+func excludedLock(requirement *gemapi.Requirement, lock *gemapi.Lock) *gemapi.ExcludedVersion {
+	if lock.Resolved.Type != gemapi.Version {
+		return nil
+	}
+
+	_, version, err := parseVersionTag(lock.Resolved.Version)
+	if err != nil {
+		return nil
+	}
+	return excludedVersion(requirement.Target.Exclude, version)
+}
+
+// narrowRange restricts every alternative of the given version range by the given constraints.
+func narrowRange(versionRange, constraints string) string {
+	alternatives := strings.Split(versionRange, "||")
+	for i, alternative := range alternatives {
+		alternatives[i] = fmt.Sprintf("%s, %s", strings.TrimSpace(alternative), constraints)
+	}
+	return strings.Join(alternatives, " || ")
+}
+
+// updateTarget returns the target to update the given lock to. If the lock was resolved from a version that still
+// satisfies the requirement, the version range is narrowed according to the update strategy of the requirement.
+func updateTarget(requirement *gemapi.Requirement, lock *gemapi.Lock) (gemapi.Target, error) {
+	target := requirement.Target
+	if lock == nil || !isRequirementSatisfiedByLock(requirement, lock) || target.Type != gemapi.Version {
+		return target, nil
+	}
+
+	_, locked, err := parseVersionTag(lock.Resolved.Version)
+	if err != nil {
+		return target, err
+	}
+
+	switch requirement.UpdateStrategy {
+	case "", gemapi.UpdateStrategyLatestInRange:
+		return target, nil
+	case gemapi.UpdateStrategyMajor:
+		target.Version = narrowRange(target.Version, fmt.Sprintf(">=%s", locked))
+	case gemapi.UpdateStrategyMinor:
+		target.Version = narrowRange(target.Version, fmt.Sprintf(">=%s, <%d.0.0", locked, locked.Major()+1))
+	case gemapi.UpdateStrategyPatch:
+		target.Version = narrowRange(target.Version, fmt.Sprintf(">=%s, <%d.%d.0", locked, locked.Major(), locked.Minor()+1))
+	default:
+		return target, fmt.Errorf("unsupported update strategy %q", requirement.UpdateStrategy)
+	}
+	return target, nil
 }
 
 func (r *repositoryInterface) Ensure(submodule string, requirement *gemapi.Requirement, lock *gemapi.Lock, update bool) (*gemapi.Lock, error) {
-	if lock == nil || update || !isRequirementSatisfiedByLock(requirement, lock) {
-		var err error
-		lock, err = r.SolveTarget(requirement.Target)
+	return r.EnsureContext(context.Background(), submodule, requirement, lock, update)
+}
+
+func (r *repositoryInterface) EnsureContext(ctx context.Context, submodule string, requirement *gemapi.Requirement, lock *gemapi.Lock, update bool) (*gemapi.Lock, error) {
+	requirement = effectiveRequirement(submodule, requirement)
+	resolved := lock == nil || update || !isRequirementSatisfiedByLock(requirement, lock)
+	if resolved {
+		target, err := updateTarget(requirement, lock)
+		if err != nil {
+			return nil, err
+		}
+
+		if lock, err = r.SolveTargetContext(ctx, target); err != nil {
+			return nil, err
+		}
+	} else if lock.Resolved.Type == gemapi.Version {
+		version, err := r.lockedVersion(ctx, lock)
 		if err != nil {
 			return nil, err
 		}
+
+		if isLegacyTagLock(lock, version) {
+			lock.Hash = version.Hash
+			lock.TagHash = version.TagHash
+		}
 	}
 	lock.Target = requirement.Target
 
-	if err := r.Verify(submodule, requirement, lock); err != nil {
+	signer, err := r.verify(ctx, submodule, requirement, lock)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.recordDigest(ctx, submodule, requirement, lock); err != nil {
+		return nil, err
+	}
+	if err := r.recordMetadata(ctx, lock, resolved); err != nil {
 		return nil, err
 	}
+	if signer != "" {
+		lock.Signer = signer
+	}
 	return lock, nil
 }
 
 func (r *repositoryInterface) Fetch(submodule string, requirement *gemapi.Requirement, lock *gemapi.Lock) ([]runtime.Object, error) {
-	path := optSubmodulePath(submodule, requirement.Filename)
-	data, err := r.repository.File(lock.Hash, path)
+	return r.FetchContext(context.Background(), submodule, requirement, lock)
+}
+
+func (r *repositoryInterface) FetchContext(ctx context.Context, submodule string, requirement *gemapi.Requirement, lock *gemapi.Lock) ([]runtime.Object, error) {
+	path, data, err := r.readFile(ctx, submodule, requirement, lock)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyDigest(lock, path, data); err != nil {
+		return nil, err
+	}
+
+	objects, err := LoadControllerRegistration(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	warnings, err := ValidateControllerRegistrationObjects(objects)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, warning := range warnings {
+		r.log.WithField("path", path).Warn(warning)
+	}
+	return objects, nil
+}
+
+// lockedVersionName returns the name of the tag a version lock was resolved from, otherwise the locked hash.
+func lockedVersionName(lock *gemapi.Lock) string {
+	if lock.Resolved.Type == gemapi.Version {
+		return lock.Resolved.Version
+	}
+	return lock.Hash
+}
+
+func (r *repositoryInterface) Outdated(submodule string, requirement *gemapi.Requirement, lock *gemapi.Lock) (*ModuleStatus, error) {
+	return r.OutdatedContext(context.Background(), submodule, requirement, lock)
+}
+
+func (r *repositoryInterface) OutdatedContext(ctx context.Context, submodule string, requirement *gemapi.Requirement, lock *gemapi.Lock) (*ModuleStatus, error) {
+	requirement = effectiveRequirement(submodule, requirement)
+	wanted, err := r.SolveTargetContext(ctx, requirement.Target)
 	if err != nil {
-		return nil, errors.Wrapf(err, "error getting file with hash %s at %s", lock.Hash, path)
+		return nil, err
+	}
+
+	status := &ModuleStatus{
+		Target: requirement.Target,
+		Wanted: lockedVersionName(wanted),
+		Latest: lockedVersionName(wanted),
+	}
+	if lock != nil {
+		status.Locked = lockedVersionName(lock)
 	}
 
-	return LoadControllerRegistration(data)
+	if requirement.Target.Type == gemapi.Version {
+		versions, err := r.repository.VersionsContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		versions = versionsWithPrefix(versions, requirement.Target.TagPrefix)
+		newest, err := bestVersion("*", versions, gemapi.PrereleasesOnlyIfNewer, requirement.Target.Exclude)
+		if err != nil {
+			return nil, err
+		}
+		status.Latest = newest.Name
+
+		if newest.Version.Prerelease() != "" {
+			status.Prerelease = newest.Name
+			status.Latest = ""
+			if latest, err := bestVersion("*", versions, gemapi.PrereleasesExclude, requirement.Target.Exclude); err == nil {
+				status.Latest = latest.Name
+			}
+		}
+	}
+	return status, nil
 }
 
 type gem struct {
 	log                 logrus.FieldLogger
 	registry            RepositoryRegistry
 	targetSolverFactory TargetSolverFactory
+	jobs                int
+	failFast            bool
+	verifier            SignatureVerifier
+	denyList            *gemapi.DenyList
+	// constraintFiles caches the constraints files read by the constraint solver.
+	constraintFiles *callCache
 }
 
 func New(log logrus.FieldLogger, registry RepositoryRegistry, targetSolverFactory TargetSolverFactory) Interface {
-	return &gem{log, registry, targetSolverFactory}
+	return &gem{log, registry, targetSolverFactory, DefaultJobs, true, nil, nil, newCallCache()}
+}
+
+func (g *gem) Jobs() int {
+	return g.jobs
+}
+
+// SetJobs sets the maximum number of modules that are processed concurrently.
+// Values smaller than one are treated as one.
+func (g *gem) SetJobs(jobs int) {
+	g.jobs = jobs
+}
+
+func (g *gem) FailFast() bool {
+	return g.failFast
+}
+
+// SetFailFast sets whether to stop at the first failing module or to process all modules.
+func (g *gem) SetFailFast(failFast bool) {
+	g.failFast = failFast
+}
+
+func (g *gem) SignatureVerifier() SignatureVerifier {
+	return g.verifier
+}
+
+func (g *gem) SetSignatureVerifier(verifier SignatureVerifier) {
+	g.verifier = verifier
+}
+
+func (g *gem) DenyList() *gemapi.DenyList {
+	return g.denyList
+}
+
+func (g *gem) SetDenyList(denyList *gemapi.DenyList) {
+	g.denyList = denyList
+}
+
+// withDeniedVersions returns the given requirement with the versions of the module denied by the deny list
+// excluded in addition to its own excluded versions.
+func (g *gem) withDeniedVersions(moduleKey gemapi.ModuleKey, requirement *gemapi.Requirement) *gemapi.Requirement {
+	if g.denyList == nil || requirement.Target.Type != gemapi.Version || len(g.denyList.Denied[moduleKey]) == 0 {
+		return requirement
+	}
+
+	out := *requirement
+	out.Target.Exclude = append(append([]gemapi.ExcludedVersion(nil), requirement.Target.Exclude...), g.denyList.Denied[moduleKey]...)
+	return &out
 }
 
 func (g *gem) Repository(repositoryName string) (RepositoryInterface, error) {
-	repo, err := g.registry.Repository(repositoryName)
+	return g.RepositoryContext(context.Background(), repositoryName)
+}
+
+func (g *gem) RepositoryContext(ctx context.Context, repositoryName string) (RepositoryInterface, error) {
+	repo, err := g.registry.RepositoryContext(ctx, repositoryName)
 	if err != nil {
 		return nil, err
 	}
 
-	return &repositoryInterface{targetSolver: g.targetSolverFactory.New(repo), repository: repo}, nil
+	return &repositoryInterface{log: g.log, targetSolver: g.targetSolverFactory.New(repo), repository: repo, verifier: g.verifier}, nil
+}
+
+// repositoryURL returns the URL the repository of the module is cloned from.
+func repositoryURL(moduleKey gemapi.ModuleKey, requirement *gemapi.Requirement) string {
+	if requirement.URL != "" {
+		return requirement.URL
+	}
+	return moduleKey.Repository
 }
 
 func withUpdateLogger(log logrus.FieldLogger, update bool) logrus.FieldLogger {
@@ -200,98 +713,370 @@ func withLockLogger(log logrus.FieldLogger, lock *gemapi.Lock) logrus.FieldLogge
 	return log.WithField("lock", lock)
 }
 
+// sortedModuleKeys returns the module keys of the given requirements, sorted by name like they are serialized.
+func sortedModuleKeys(requirements *gemapi.Requirements) []gemapi.ModuleKey {
+	keys := make([]gemapi.ModuleKey, 0, len(requirements.Requirements))
+	for moduleKey := range requirements.Requirements {
+		keys = append(keys, moduleKey)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	return keys
+}
+
+// forEachModule calls f for each module of the requirements, running at most g.jobs calls concurrently.
+// Errors are returned as ModuleErrors, sorted by module name so the result does not depend on scheduling.
+// If g.failFast is set, no further calls are started after the first failure. Once the context is done,
+// the modules that have not been started yet fail with the error of the context.
+// The requirements f is called with exclude the versions denied by the deny list, if any.
+func (g *gem) forEachModule(ctx context.Context, requirements *gemapi.Requirements, f func(i int, moduleKey gemapi.ModuleKey, requirement *gemapi.Requirement) error) error {
+	keys := sortedModuleKeys(requirements)
+	jobs := g.jobs
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	var (
+		wg     sync.WaitGroup
+		sem    = make(chan struct{}, jobs)
+		errs   = make([]error, len(keys))
+		failed int32
+	)
+	for i, moduleKey := range keys {
+		if g.failFast && atomic.LoadInt32(&failed) != 0 {
+			break
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, moduleKey gemapi.ModuleKey) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if err := f(i, moduleKey, g.withDeniedVersions(moduleKey, requirements.Requirements[moduleKey])); err != nil {
+				errs[i] = err
+				atomic.StoreInt32(&failed, 1)
+			}
+		}(i, moduleKey)
+	}
+	wg.Wait()
+
+	var moduleErrs ModuleErrors
+	for i, err := range errs {
+		if err != nil {
+			moduleErrs = append(moduleErrs, &ModuleError{ModuleKey: keys[i], Requirement: requirements.Requirements[keys[i]], Err: err})
+		}
+	}
+	if len(moduleErrs) == 0 {
+		return nil
+	}
+	if g.failFast {
+		return moduleErrs[0]
+	}
+	return moduleErrs
+}
+
+// partialResult returns the locks of the successful modules unless failing fast.
+func (g *gem) partialResult(locks *gemapi.Locks) *gemapi.Locks {
+	if g.failFast {
+		return nil
+	}
+	return locks
+}
+
 func (g *gem) Solve(requirements *gemapi.Requirements) (*gemapi.Locks, error) {
-	locks := make(map[gemapi.ModuleKey]*gemapi.Lock)
+	return g.SolveContext(context.Background(), requirements)
+}
+
+// SolveContext resolves the requirements of all modules. The versions are chosen together such that the
+// constraints declared by the modules and their requirements hold; a ConstraintConflictError explains why
+// they cannot.
+func (g *gem) SolveContext(ctx context.Context, requirements *gemapi.Requirements) (*gemapi.Locks, error) {
+	var (
+		mu    sync.Mutex
+		locks = make(map[gemapi.ModuleKey]*gemapi.Lock)
+	)
+
+	header, err := g.locksHeader(requirements)
+	if err != nil {
+		return nil, fmt.Errorf("could not compute locks header: %w", err)
+	}
+
+	chosen, err := g.solveConstraints(ctx, requirements, func(moduleKey gemapi.ModuleKey, requirement *gemapi.Requirement) (*constrainedModule, error) {
+		return g.constrainedModule(ctx, moduleKey, requirement, nil, false)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not solve constraints: %w", err)
+	}
 
-	for moduleKey, requirement := range requirements.Requirements {
+	if err := g.forEachModule(ctx, requirements, func(_ int, moduleKey gemapi.ModuleKey, requirement *gemapi.Requirement) error {
 		log := withModuleKeyRequirementLogger(g.log, moduleKey, requirement)
 		log.Info("Solving")
 
 		log.Debug("Retrieving repository")
-		repositoryInterface, err := g.Repository(moduleKey.Repository)
+		repositoryInterface, err := g.RepositoryContext(ctx, repositoryURL(moduleKey, requirement))
 		if err != nil {
-			return nil, fmt.Errorf("could not retrieve repository %q: %w", &moduleKey, err)
+			return fmt.Errorf("could not retrieve repository: %w", err)
 		}
 
 		log.Debug("Solving requirement")
-		lock, err := repositoryInterface.Solve(moduleKey.Submodule, requirement)
+		lock, err := repositoryInterface.SolveContext(ctx, moduleKey.Submodule, pinnedRequirement(requirement, chosen[moduleKey]))
 		if err != nil {
-			return nil, fmt.Errorf("could not solve requirement %q for extension %q: %w", &requirement.Target, &moduleKey, err)
+			return fmt.Errorf("could not solve requirement: %w", err)
 		}
+		lock.Target = effectiveRequirement(moduleKey.Submodule, requirement).Target
 
 		log = withLockLogger(log, lock)
 		log.Info("Successfully solved")
+		mu.Lock()
+		defer mu.Unlock()
 		locks[moduleKey] = lock
+		return nil
+	}); err != nil {
+		return g.partialResult(&gemapi.Locks{Header: header, Locks: locks}), err
 	}
 
-	return &gemapi.Locks{Locks: locks}, nil
+	return &gemapi.Locks{Header: header, Locks: locks}, nil
 }
 
 func (g *gem) Fetch(requirements *gemapi.Requirements, locks *gemapi.Locks) ([]runtime.Object, error) {
-	var registrations []runtime.Object
+	return g.FetchContext(context.Background(), requirements, locks)
+}
+
+// FetchContext returns the registrations of all modules, ordered by module name and,
+// within a module, by their order in the registration file.
+func (g *gem) FetchContext(ctx context.Context, requirements *gemapi.Requirements, locks *gemapi.Locks) ([]runtime.Object, error) {
+	fetched := make([][]runtime.Object, len(requirements.Requirements))
 
-	for moduleKey, requirement := range requirements.Requirements {
+	err := g.forEachModule(ctx, requirements, func(i int, moduleKey gemapi.ModuleKey, requirement *gemapi.Requirement) error {
 		log := withModuleKeyRequirementLogger(g.log, moduleKey, requirement)
 		log.Info("Fetching")
 
 		log.Debug("Retrieving repository")
-		repositoryInterface, err := g.Repository(moduleKey.Repository)
+		repositoryInterface, err := g.RepositoryContext(ctx, repositoryURL(moduleKey, requirement))
 		if err != nil {
-			return nil, fmt.Errorf("could not retrieve repository %q: %w", &moduleKey, err)
+			return fmt.Errorf("could not retrieve repository: %w", err)
 		}
 
 		log.Debug("Checking whether lock is present")
 		lock, ok := locks.Locks[moduleKey]
 		if !ok {
-			return nil, fmt.Errorf("no lock recorded for %q", &moduleKey)
+			return fmt.Errorf("no lock recorded")
 		}
 
 		log.Debug("Fetching controller installation")
-		registration, err := repositoryInterface.Fetch(moduleKey.Submodule, requirement, lock)
+		registration, err := repositoryInterface.FetchContext(ctx, moduleKey.Submodule, requirement, lock)
 		if err != nil {
-			return nil, errors.Wrapf(err, "could not fetch registration for %q", &moduleKey)
+			return errors.Wrap(err, "could not fetch registration")
 		}
 
 		log.Info("Successfully fetched")
+		fetched[i] = registration
+		return nil
+	})
+
+	var registrations []runtime.Object
+	for _, registration := range fetched {
 		registrations = append(registrations, registration...)
 	}
+	if err != nil {
+		if g.failFast {
+			return nil, err
+		}
+		return registrations, err
+	}
 
+	conflicts, err := detectConflicts(requirements, fetched)
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicts) > 0 {
+		return nil, &ResourceConflictError{conflicts}
+	}
 	return registrations, nil
 }
 
 func (g *gem) Ensure(requirements *gemapi.Requirements, locks *gemapi.Locks, updatePolicy UpdatePolicy) (*gemapi.Locks, error) {
-	newLocks := make(map[gemapi.ModuleKey]*gemapi.Lock)
-	for moduleKey, requirement := range requirements.Requirements {
+	return g.EnsureContext(context.Background(), requirements, locks, updatePolicy)
+}
+
+// moduleLock returns the lock of the module, if any.
+func moduleLock(locks *gemapi.Locks, moduleKey gemapi.ModuleKey) *gemapi.Lock {
+	if locks == nil {
+		return nil
+	}
+	return locks.Locks[moduleKey]
+}
+
+// lockedAll reports whether every module of the requirements has a lock.
+func lockedAll(requirements *gemapi.Requirements, locks *gemapi.Locks) bool {
+	for moduleKey := range requirements.Requirements {
+		if moduleLock(locks, moduleKey) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// updatesAny reports whether the policy updates any module of the requirements.
+func updatesAny(policy UpdatePolicy, requirements *gemapi.Requirements) bool {
+	for moduleKey := range requirements.Requirements {
+		if policy.ShouldUpdateModule(moduleKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// withUpdateStrategy returns the requirement with the update strategy the policy chooses for the module.
+func withUpdateStrategy(policy UpdatePolicy, moduleKey gemapi.ModuleKey, requirement *gemapi.Requirement) *gemapi.Requirement {
+	strategy := updateStrategy(policy, moduleKey, requirement)
+	if strategy == requirement.UpdateStrategy {
+		return requirement
+	}
+
+	out := *requirement
+	out.UpdateStrategy = strategy
+	return &out
+}
+
+// EnsureContext keeps the locks that satisfy the requirements and resolves the others like SolveContext.
+// Locks are resolved anew if they conflict with the constraints of the other modules.
+func (g *gem) EnsureContext(ctx context.Context, requirements *gemapi.Requirements, locks *gemapi.Locks, updatePolicy UpdatePolicy) (*gemapi.Locks, error) {
+	var (
+		mu       sync.Mutex
+		newLocks = make(map[gemapi.ModuleKey]*gemapi.Lock)
+	)
+
+	header, err := g.locksHeader(requirements)
+	if err != nil {
+		return nil, fmt.Errorf("could not compute locks header: %w", err)
+	}
+	changed, err := RequirementsChanged(requirements, g.denyList, locks)
+	if err != nil {
+		return nil, fmt.Errorf("could not compare requirements digest: %w", err)
+	}
+	if changed && locks != nil && locks.Header.RequirementsDigest != "" {
+		g.log.Info("Requirements changed since the locks were resolved")
+	}
+
+	// Locks resolved from the same requirements and deny list already satisfy the constraints of each other,
+	// since the constraints declared at the locked commits cannot change.
+	var chosen map[gemapi.ModuleKey]*candidate
+	if changed || !lockedAll(requirements, locks) || updatesAny(updatePolicy, requirements) {
+		if chosen, err = g.solveConstraints(ctx, requirements, func(moduleKey gemapi.ModuleKey, requirement *gemapi.Requirement) (*constrainedModule, error) {
+			update := updatePolicy.ShouldUpdateModule(moduleKey)
+			if update {
+				requirement = withUpdateStrategy(updatePolicy, moduleKey, requirement)
+			}
+			return g.constrainedModule(ctx, moduleKey, requirement, moduleLock(locks, moduleKey), update)
+		}); err != nil {
+			return nil, fmt.Errorf("could not solve constraints: %w", err)
+		}
+	} else {
+		g.log.Debug("Requirements unchanged since the locks were resolved, keeping the locked versions")
+	}
+
+	if err := g.forEachModule(ctx, requirements, func(_ int, moduleKey gemapi.ModuleKey, requirement *gemapi.Requirement) error {
 		update := updatePolicy.ShouldUpdateModule(moduleKey)
 		log := withUpdateLogger(withModuleKeyRequirementLogger(g.log, moduleKey, requirement), update)
 		log.Info("Ensuring")
 
 		log.Debug("Retrieving repository")
-		repositoryInterface, err := g.Repository(moduleKey.Repository)
+		repositoryInterface, err := g.RepositoryContext(ctx, repositoryURL(moduleKey, requirement))
 		if err != nil {
-			return nil, fmt.Errorf("could not retrieve repository %q: %w", &moduleKey, err)
+			return fmt.Errorf("could not retrieve repository: %w", err)
 		}
 
 		log.Debug("Checking for old lock")
-		var oldLock *gemapi.Lock
-		if locks != nil {
-			oldLock = locks.Locks[moduleKey]
-			if oldLock != nil {
-				log = withLockLogger(log, oldLock)
-				log.Debug("Old lock found")
+		oldLock := moduleLock(locks, moduleKey)
+		if oldLock != nil {
+			log = withLockLogger(log, oldLock)
+			log.Debug("Old lock found")
+			if excluded := excludedLock(requirement, oldLock); excluded != nil {
+				log.WithField("reason", exclusionReason(excluded)).Warn("Locked version is excluded, resolving anew")
 			}
 		}
 
+		if update {
+			requirement = withUpdateStrategy(updatePolicy, moduleKey, requirement)
+			log = log.WithField("updateStrategy", requirement.UpdateStrategy)
+		}
+
+		pinned := pinnedRequirement(requirement, chosen[moduleKey])
+		if oldLock != nil && !update && isRequirementSatisfiedByLock(effectiveRequirement(moduleKey.Submodule, requirement), oldLock) &&
+			!isRequirementSatisfiedByLock(effectiveRequirement(moduleKey.Submodule, pinned), oldLock) {
+			log.WithField("version", chosen[moduleKey]).Info("Locked version conflicts with constraints, resolving anew")
+		}
+
 		log.Debug("Ensuring requirement with optional lock")
-		lock, err := repositoryInterface.Ensure(moduleKey.Submodule, requirement, oldLock, update)
+		lock, err := repositoryInterface.EnsureContext(ctx, moduleKey.Submodule, pinned, oldLock, update)
 		if err != nil {
-			return nil, fmt.Errorf("could not ensure requirement %q for repository %q: %w", requirement, &moduleKey, err)
+			return fmt.Errorf("could not ensure requirement: %w", err)
 		}
+		lock.Target = effectiveRequirement(moduleKey.Submodule, requirement).Target
 
 		log = withLockLogger(log, lock)
 		log.Info("Successfully ensured")
+		mu.Lock()
+		defer mu.Unlock()
 		newLocks[moduleKey] = lock
+		return nil
+	}); err != nil {
+		return g.partialResult(&gemapi.Locks{Header: header, Locks: newLocks}), err
 	}
 
-	return &gemapi.Locks{Locks: newLocks}, nil
+	return &gemapi.Locks{Header: header, Locks: newLocks}, nil
+}
+
+func (g *gem) Outdated(requirements *gemapi.Requirements, locks *gemapi.Locks) ([]ModuleStatus, error) {
+	return g.OutdatedContext(context.Background(), requirements, locks)
+}
+
+// OutdatedContext returns the status of all modules, ordered by module name.
+func (g *gem) OutdatedContext(ctx context.Context, requirements *gemapi.Requirements, locks *gemapi.Locks) ([]ModuleStatus, error) {
+	statuses := make([]*ModuleStatus, len(requirements.Requirements))
+
+	err := g.forEachModule(ctx, requirements, func(i int, moduleKey gemapi.ModuleKey, requirement *gemapi.Requirement) error {
+		log := withModuleKeyRequirementLogger(g.log, moduleKey, requirement)
+		log.Info("Checking for newer versions")
+
+		log.Debug("Retrieving repository")
+		repositoryInterface, err := g.RepositoryContext(ctx, repositoryURL(moduleKey, requirement))
+		if err != nil {
+			return fmt.Errorf("could not retrieve repository: %w", err)
+		}
+
+		var lock *gemapi.Lock
+		if locks != nil {
+			lock = locks.Locks[moduleKey]
+		}
+
+		status, err := repositoryInterface.OutdatedContext(ctx, moduleKey.Submodule, requirement, lock)
+		if err != nil {
+			return fmt.Errorf("could not determine versions: %w", err)
+		}
+
+		status.ModuleKey = moduleKey
+		statuses[i] = status
+		return nil
+	})
+	if err != nil && g.failFast {
+		return nil, err
+	}
+
+	var out []ModuleStatus
+	for _, status := range statuses {
+		if status != nil {
+			out = append(out, *status)
+		}
+	}
+	return out, err
 }
@@ -15,32 +15,89 @@
 package gem
 
 import (
+	"context"
+	"fmt"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
 	"io"
+	"io/ioutil"
 	"net/url"
+	"regexp"
+	"strings"
+	"sync"
 
 	"github.com/Masterminds/semver"
+	gemapi "github.com/gardener/gem/pkg/gem/api"
+	gemioutil "github.com/gardener/gem/pkg/util/io"
 	"gopkg.in/src-d/go-git.v4"
 	"gopkg.in/src-d/go-git.v4/plumbing"
 	"gopkg.in/src-d/go-git.v4/storage/memory"
 )
 
-type gitRepositoryRegistry struct{}
+type gitRepositoryRegistry struct {
+	auth AuthProvider
+}
+
+// GitRepositoryRegistry clones repositories anonymously into memory.
+var GitRepositoryRegistry = NewGitRepositoryRegistry(NoAuth)
+
+// NewGitRepositoryRegistry creates a new RepositoryRegistry that clones repositories into memory,
+// authenticating with the credentials of the given AuthProvider.
+func NewGitRepositoryRegistry(auth AuthProvider) RepositoryRegistry {
+	return gitRepositoryRegistry{auth}
+}
+
+// scpLikeURLRegex matches the scp-like syntax `[user@]host:path` that git accepts for repositories
+// accessed via ssh.
+var scpLikeURLRegex = regexp.MustCompile(`^(?:([^@/:]+)@)?([^@/:]+):(.+)$`)
 
-var GitRepositoryRegistry RepositoryRegistry = gitRepositoryRegistry{}
+// parseRepositoryURL parses the given repository name. Names without a scheme are accessed via https,
+// scp-like names via ssh: `git@github.com:org/repo` is the same as `ssh://git@github.com/org/repo`.
+func parseRepositoryURL(name string) (*url.URL, error) {
+	if !strings.Contains(name, "://") {
+		if match := scpLikeURLRegex.FindStringSubmatch(name); match != nil {
+			u := &url.URL{Scheme: "ssh", Host: match[2], Path: "/" + strings.TrimPrefix(match[3], "/")}
+			if match[1] != "" {
+				u.User = url.User(match[1])
+			}
+			return u, nil
+		}
+	}
 
-func (gitRepositoryRegistry) Repository(name string) (Repository, error) {
 	u, err := url.Parse(name)
 	if err != nil {
 		return nil, err
 	}
 
 	if u.Scheme == "" {
-		u.Scheme = "https"
+		if u, err = url.Parse("https://" + name); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, ok := u.User.Password(); ok {
+		return nil, fmt.Errorf("repository %s must not contain a password, configure credentials instead", u.Redacted())
 	}
+	return u, nil
+}
 
-	repo, err := git.Clone(memory.NewStorage(), nil, &git.CloneOptions{
+func (r gitRepositoryRegistry) Repository(name string) (Repository, error) {
+	return r.RepositoryContext(context.Background(), name)
+}
+
+func (r gitRepositoryRegistry) RepositoryContext(ctx context.Context, name string) (Repository, error) {
+	u, err := parseRepositoryURL(name)
+	if err != nil {
+		return nil, err
+	}
+
+	auth, err := r.auth.Auth(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := git.CloneContext(ctx, memory.NewStorage(), nil, &git.CloneOptions{
 		URL:        u.String(),
+		Auth:       auth,
 		NoCheckout: true,
 	})
 	if err != nil {
@@ -50,15 +107,46 @@ func (gitRepositoryRegistry) Repository(name string) (Repository, error) {
 	return NewGitRepository(repo), nil
 }
 
+// parseVersionTag splits the given tag name into an optional prefix ending with a slash and a semantic version.
+func parseVersionTag(name string) (string, *semver.Version, error) {
+	idx := strings.LastIndexByte(name, '/')
+	v, err := semver.NewVersion(name[idx+1:])
+	if err != nil {
+		return "", nil, err
+	}
+	return name[:idx+1], v, nil
+}
+
+func optHashString(hash plumbing.Hash) string {
+	if hash.IsZero() {
+		return ""
+	}
+	return hash.String()
+}
+
+// gitRepository serializes all accesses to the underlying repository since
+// the go-git storages are not safe for concurrent use.
 type gitRepository struct {
+	mu   sync.Mutex
 	repo *git.Repository
 }
 
 func NewGitRepository(repo *git.Repository) Repository {
-	return &gitRepository{repo}
+	return &gitRepository{repo: repo}
 }
 
 func (g *gitRepository) Revision(name string) (string, error) {
+	return g.RevisionContext(context.Background(), name)
+}
+
+func (g *gitRepository) RevisionContext(_ context.Context, name string) (string, error) {
+	if !gemapi.IsCommitHash(name) {
+		return "", fmt.Errorf("revision %q is not a full commit hash", name)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
 	commit, err := g.repo.CommitObject(plumbing.NewHash(name))
 	if err != nil {
 		return "", err
@@ -68,6 +156,13 @@ func (g *gitRepository) Revision(name string) (string, error) {
 }
 
 func (g *gitRepository) Branch(name string) (string, error) {
+	return g.BranchContext(context.Background(), name)
+}
+
+func (g *gitRepository) BranchContext(_ context.Context, name string) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
 	ref, err := g.repo.Reference(plumbing.NewRemoteReferenceName("origin", name), true)
 	if err != nil {
 		return "", err
@@ -76,7 +171,37 @@ func (g *gitRepository) Branch(name string) (string, error) {
 	return ref.Hash().String(), nil
 }
 
+// peel resolves the given hash to the hash of the commit it points to. If the hash refers to
+// an annotated tag, the hash of the (outermost) tag object is returned as well.
+func (g *gitRepository) peel(hash plumbing.Hash) (commit, tag plumbing.Hash, err error) {
+	t, err := g.repo.TagObject(hash)
+	if err != nil {
+		if err == plumbing.ErrObjectNotFound {
+			return hash, plumbing.ZeroHash, nil
+		}
+		return plumbing.ZeroHash, plumbing.ZeroHash, err
+	}
+
+	for t.TargetType == plumbing.TagObject {
+		if t, err = g.repo.TagObject(t.Target); err != nil {
+			return plumbing.ZeroHash, plumbing.ZeroHash, err
+		}
+	}
+	if t.TargetType != plumbing.CommitObject {
+		return plumbing.ZeroHash, plumbing.ZeroHash, object.ErrUnsupportedObject
+	}
+
+	return t.Target, hash, nil
+}
+
 func (g *gitRepository) Versions() ([]RepositoryVersion, error) {
+	return g.VersionsContext(context.Background())
+}
+
+func (g *gitRepository) VersionsContext(context.Context) ([]RepositoryVersion, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
 	tags, err := g.repo.Tags()
 	if err != nil {
 		return nil, err
@@ -85,15 +210,25 @@ func (g *gitRepository) Versions() ([]RepositoryVersion, error) {
 	var versions []RepositoryVersion
 	if err := tags.ForEach(func(ref *plumbing.Reference) error {
 		name := ref.Name().Short()
-		r, err := semver.NewVersion(name)
+		prefix, r, err := parseVersionTag(name)
 		if err != nil {
 			return nil
 		}
 
+		commit, tag, err := g.peel(ref.Hash())
+		if err != nil {
+			if err == object.ErrUnsupportedObject {
+				return nil
+			}
+			return err
+		}
+
 		versions = append(versions, RepositoryVersion{
 			Version: *r,
 			Name:    name,
-			Hash:    ref.Hash().String(),
+			Prefix:  prefix,
+			Hash:    commit.String(),
+			TagHash: optHashString(tag),
 		})
 		return nil
 	}); err != nil {
@@ -104,6 +239,13 @@ func (g *gitRepository) Versions() ([]RepositoryVersion, error) {
 }
 
 func (g *gitRepository) Latest() (string, error) {
+	return g.LatestContext(context.Background())
+}
+
+func (g *gitRepository) LatestContext(context.Context) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
 	head, err := g.repo.Head()
 	if err != nil {
 		return "", err
@@ -113,7 +255,12 @@ func (g *gitRepository) Latest() (string, error) {
 }
 
 func (g *gitRepository) fileObject(hash, path string) (*object.File, error) {
-	commit, err := g.repo.CommitObject(plumbing.NewHash(hash))
+	commitHash, _, err := g.peel(plumbing.NewHash(hash))
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := g.repo.CommitObject(commitHash)
 	if err != nil {
 		return nil, err
 	}
@@ -122,20 +269,35 @@ func (g *gitRepository) fileObject(hash, path string) (*object.File, error) {
 }
 
 func (g *gitRepository) File(hash, path string) (io.Reader, error) {
+	return g.FileContext(context.Background(), hash, path)
+}
+
+func (g *gitRepository) FileContext(_ context.Context, hash, path string) (io.Reader, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
 	file, err := g.fileObject(hash, path)
 	if err != nil {
 		return nil, err
 	}
 
-	r, err := file.Reader()
+	// Read the contents while holding the lock, objects are read lazily from the storage.
+	contents, err := file.Contents()
 	if err != nil {
 		return nil, err
 	}
 
-	return r, nil
+	return strings.NewReader(contents), nil
 }
 
 func (g *gitRepository) HasFile(hash, path string) (bool, error) {
+	return g.HasFileContext(context.Background(), hash, path)
+}
+
+func (g *gitRepository) HasFileContext(_ context.Context, hash, path string) (bool, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
 	if _, err := g.fileObject(hash, path); err != nil {
 		if err == object.ErrFileNotFound {
 			return false, nil
@@ -144,3 +306,59 @@ func (g *gitRepository) HasFile(hash, path string) (bool, error) {
 	}
 	return true, nil
 }
+
+// subject returns the first line of the given message.
+func subject(message string) string {
+	return strings.TrimSpace(strings.SplitN(message, "\n", 2)[0])
+}
+
+func (g *gitRepository) ObjectInfo(hash string) (*ObjectInfo, error) {
+	return g.ObjectInfoContext(context.Background(), hash)
+}
+
+func (g *gitRepository) ObjectInfoContext(_ context.Context, hash string) (*ObjectInfo, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	obj, err := g.repo.Object(plumbing.AnyObject, plumbing.NewHash(hash))
+	if err != nil {
+		return nil, err
+	}
+
+	switch o := obj.(type) {
+	case *object.Commit:
+		return &ObjectInfo{Time: o.Committer.When, Subject: subject(o.Message)}, nil
+	case *object.Tag:
+		return &ObjectInfo{Time: o.Tagger.When, Subject: subject(o.Message)}, nil
+	default:
+		return nil, object.ErrUnsupportedObject
+	}
+}
+
+func (g *gitRepository) Signature(hash string) (string, []byte, error) {
+	return g.SignatureContext(context.Background(), hash)
+}
+
+func (g *gitRepository) SignatureContext(_ context.Context, hash string) (string, []byte, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	obj, err := g.repo.Storer.EncodedObject(plumbing.AnyObject, plumbing.NewHash(hash))
+	if err != nil {
+		return "", nil, err
+	}
+
+	reader, err := obj.Reader()
+	if err != nil {
+		return "", nil, err
+	}
+	defer gemioutil.CloseSilently(reader)
+
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return "", nil, err
+	}
+
+	signature, payload := parseSignedObject(obj.Type(), data)
+	return signature, payload, nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gem
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// initTestRepository initializes a git repository with a worktree in a temporary directory.
+func initTestRepository(t *testing.T) (string, *git.Repository) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, repo
+}
+
+// commitTestFiles writes the given files into the worktree of the repository and commits them.
+func commitTestFiles(t *testing.T, dir string, repo *git.Repository, files map[string]string) plumbing.Hash {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range files {
+		if err := writeFile(filepath.Join(dir, name), []byte(data)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hash, err := worktree.Commit("Update files", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+func TestGitRepositoryRevision(t *testing.T) {
+	dir, repo := initTestRepository(t)
+	hash := commitTestFiles(t, dir, repo, map[string]string{"README.md": "test\n"})
+	if _, err := repo.CreateTag("v1.0.0", hash, nil); err != nil {
+		t.Fatal(err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repository := NewGitRepository(repo)
+	for _, tc := range []struct {
+		name string
+		err  bool
+	}{
+		{name: hash.String()},
+		{name: strings.ToUpper(hash.String())},
+		{name: "v1.0.0", err: true},
+		{name: head.Name().Short(), err: true},
+		{name: hash.String()[:7], err: true},
+		{name: strings.Repeat("0", 40), err: true},
+	} {
+		got, err := repository.Revision(tc.name)
+		if tc.err {
+			if err == nil {
+				t.Fatalf("Revision(%q) returned %s, want an error", tc.name, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Revision(%q): %v", tc.name, err)
+		}
+		if got != hash.String() {
+			t.Fatalf("Revision(%q) returned %s, want %s", tc.name, got, hash)
+		}
+	}
+}
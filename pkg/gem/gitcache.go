@@ -0,0 +1,462 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gem
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gardener/gem/pkg/util/flock"
+	"gopkg.in/src-d/go-billy.v4/osfs"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/cache"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	"gopkg.in/src-d/go-git.v4/storage/filesystem"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
+)
+
+const (
+	gitCacheRepositoriesDir = "repositories"
+	gitCacheLocksDir        = "locks"
+	gitCacheEntrySuffix     = ".git"
+	gitCacheLockSuffix      = ".lock"
+	gitCacheUseLockSuffix   = ".use.lock"
+)
+
+// gitCacheFetchRefSpecs are used to update a cached clone. The local branches are updated as well
+// so that HEAD of the bare clone keeps pointing to the most recent commit of the default branch.
+var gitCacheFetchRefSpecs = []config.RefSpec{
+	"+refs/heads/*:refs/remotes/origin/*",
+	"+refs/heads/*:refs/heads/*",
+}
+
+// GitCacheEntry is a single repository stored in a GitCache.
+type GitCacheEntry struct {
+	Name     string
+	Path     string
+	Size     int64
+	LastUsed time.Time
+}
+
+type gitCache struct {
+	mu   sync.RWMutex
+	dir  string
+	auth AuthProvider
+
+	// inUse are the shared use locks of the entries returned by Repository, by lock path. They are held
+	// until Close so that the entries are not removed while their objects are read.
+	inUseMu sync.Mutex
+	inUse   map[string]*flock.Lock
+}
+
+// NewGitCache creates a new GitCache that stores bare clones below the given directory.
+// If the directory is empty, repositories are cloned into memory instead.
+func NewGitCache(dir string, auth AuthProvider) GitCache {
+	return &gitCache{dir: dir, auth: auth, inUse: make(map[string]*flock.Lock)}
+}
+
+func (c *gitCache) Dir() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.dir
+}
+
+func (c *gitCache) SetDir(dir string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dir = dir
+}
+
+func (c *gitCache) entryPath(key string) string {
+	return filepath.Join(c.Dir(), gitCacheRepositoriesDir, filepath.FromSlash(key)+gitCacheEntrySuffix)
+}
+
+func (c *gitCache) lockPath(key string) string {
+	return filepath.Join(c.Dir(), gitCacheLocksDir, filepath.FromSlash(key)+gitCacheLockSuffix)
+}
+
+// useLockPath is the path of the lock that is held shared while an entry is in use and exclusively
+// while it is removed.
+func (c *gitCache) useLockPath(key string) string {
+	return filepath.Join(c.Dir(), gitCacheLocksDir, filepath.FromSlash(key)+gitCacheUseLockSuffix)
+}
+
+// use marks the entry as in use by this process until Close.
+func (c *gitCache) use(ctx context.Context, key string) error {
+	c.inUseMu.Lock()
+	defer c.inUseMu.Unlock()
+
+	path := c.useLockPath(key)
+	if _, ok := c.inUse[path]; ok {
+		return nil
+	}
+
+	lock := flock.New(path)
+	if err := lock.RLockContext(ctx); err != nil {
+		return err
+	}
+	c.inUse[path] = lock
+	return nil
+}
+
+// Close releases the entries in use, after which the repositories returned by Repository must not be
+// used anymore.
+func (c *gitCache) Close() error {
+	c.inUseMu.Lock()
+	defer c.inUseMu.Unlock()
+
+	var errs []error
+	for path, lock := range c.inUse {
+		if err := lock.Unlock(); err != nil {
+			errs = append(errs, fmt.Errorf("could not release %s: %w", path, err))
+		}
+		delete(c.inUse, path)
+	}
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// gitCacheKey computes the relative, slash-separated location of a repository in the cache.
+func gitCacheKey(repositoryURL string) (string, error) {
+	u, err := parseRepositoryURL(repositoryURL)
+	if err != nil {
+		return "", err
+	}
+
+	key := strings.TrimSuffix(strings.Trim(u.Host+u.Path, "/"), gitCacheEntrySuffix)
+	if key == "" || strings.Contains("/"+key+"/", "/../") {
+		return "", fmt.Errorf("invalid repository name %q", repositoryURL)
+	}
+	return key, nil
+}
+
+func (c *gitCache) Repository(name string) (Repository, error) {
+	return c.RepositoryContext(context.Background(), name)
+}
+
+func (c *gitCache) RepositoryContext(ctx context.Context, name string) (Repository, error) {
+	if c.Dir() == "" {
+		return NewGitRepositoryRegistry(c.auth).RepositoryContext(ctx, name)
+	}
+
+	u, err := parseRepositoryURL(name)
+	if err != nil {
+		return nil, err
+	}
+
+	auth, err := c.auth.Auth(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := gitCacheKey(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.use(ctx, key); err != nil {
+		return nil, fmt.Errorf("could not lock cache entry for %s: %w", name, err)
+	}
+
+	lock := flock.New(c.lockPath(key))
+	if err := lock.LockContext(ctx); err != nil {
+		return nil, fmt.Errorf("could not lock cache entry for %s: %w", name, err)
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	path := c.entryPath(key)
+	storage, err := c.update(ctx, path, u.String(), auth)
+	if err != nil {
+		return nil, err
+	}
+
+	// Snapshot the references while still holding the lock, so concurrent fetches
+	// of other processes cannot be observed half-written. Objects are never removed
+	// by a fetch, hence they can safely be read from disk afterwards.
+	snapshot, err := newReferenceSnapshotStorage(storage)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := git.Open(snapshot, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	return NewGitRepository(repo), nil
+}
+
+// update fetches the changes of the cached clone at the given path or clones the repository if there is none
+// yet, and returns the storage of the clone.
+func (c *gitCache) update(ctx context.Context, path, url string, auth transport.AuthMethod) (*filesystem.Storage, error) {
+	if _, err := os.Stat(path); err == nil {
+		storage := filesystem.NewStorage(osfs.New(path), cache.NewObjectLRUDefault())
+		repo, err := git.Open(storage, nil)
+		if err == nil {
+			if err := repo.FetchContext(ctx, &git.FetchOptions{
+				RefSpecs: gitCacheFetchRefSpecs,
+				Auth:     auth,
+				Tags:     git.AllTags,
+				Force:    true,
+			}); err != nil && err != git.NoErrAlreadyUpToDate {
+				return nil, fmt.Errorf("could not fetch %s into %s: %w", url, path, err)
+			}
+
+			headRemoved, err := pruneGitCacheReferences(ctx, storage, url, auth)
+			if err != nil {
+				return nil, fmt.Errorf("could not prune references of %s in %s: %w", url, path, err)
+			}
+			if !headRemoved {
+				return storage, nil
+			}
+		}
+
+		// The entry is corrupt, e.g. due to an interrupted clone, or its default branch was removed. Start over
+		// with a new storage, the old one caches the removed packfiles.
+		if err := os.RemoveAll(path); err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	storage := filesystem.NewStorage(osfs.New(path), cache.NewObjectLRUDefault())
+	if _, err := git.CloneContext(ctx, storage, nil, &git.CloneOptions{
+		URL:        url,
+		Auth:       auth,
+		NoCheckout: true,
+	}); err != nil {
+		_ = os.RemoveAll(path)
+		return nil, fmt.Errorf("could not clone %s into %s: %w", url, path, err)
+	}
+	return storage, nil
+}
+
+// pruneGitCacheReferences removes the branches and tags of a cached clone that the remote no longer advertises,
+// since fetching never removes references. It returns whether HEAD pointed to one of the removed branches.
+func pruneGitCacheReferences(ctx context.Context, storage *filesystem.Storage, url string, auth transport.AuthMethod) (bool, error) {
+	advertised, err := listRemote(ctx, url, auth)
+	if err != nil {
+		return false, err
+	}
+
+	iter, err := storage.IterReferences()
+	if err != nil {
+		return false, err
+	}
+
+	var removed []plumbing.ReferenceName
+	if err := iter.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference {
+			return nil
+		}
+
+		name := ref.Name()
+		var remoteName string
+		switch {
+		case name.IsBranch(), name.IsTag():
+			remoteName = name.String()
+		case name.IsRemote() && strings.HasPrefix(name.String(), "refs/remotes/origin/"):
+			remoteName = "refs/heads/" + strings.TrimPrefix(name.String(), "refs/remotes/origin/")
+		default:
+			return nil
+		}
+		if _, ok := advertised.References[remoteName]; !ok {
+			removed = append(removed, name)
+		}
+		return nil
+	}); err != nil {
+		return false, err
+	}
+
+	for _, name := range removed {
+		if err := storage.RemoveReference(name); err != nil {
+			return false, err
+		}
+	}
+
+	head, err := storage.Reference(plumbing.HEAD)
+	if err != nil {
+		return false, err
+	}
+	for _, name := range removed {
+		if head.Type() == plumbing.SymbolicReference && head.Target() == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// referenceSnapshotStorage reads objects from the underlying filesystem storage but
+// serves references from an in-memory copy.
+type referenceSnapshotStorage struct {
+	*filesystem.Storage
+	memory.ReferenceStorage
+}
+
+func newReferenceSnapshotStorage(storage *filesystem.Storage) (*referenceSnapshotStorage, error) {
+	refs := make(memory.ReferenceStorage)
+	iter, err := storage.IterReferences()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := iter.ForEach(func(ref *plumbing.Reference) error {
+		return refs.SetReference(ref)
+	}); err != nil {
+		return nil, err
+	}
+
+	head, err := storage.Reference(plumbing.HEAD)
+	if err != nil {
+		return nil, err
+	}
+	if err := refs.SetReference(head); err != nil {
+		return nil, err
+	}
+
+	return &referenceSnapshotStorage{storage, refs}, nil
+}
+
+func (c *gitCache) List() ([]GitCacheEntry, error) {
+	if c.Dir() == "" {
+		return nil, nil
+	}
+
+	root := filepath.Join(c.Dir(), gitCacheRepositoriesDir)
+	var entries []GitCacheEntry
+	if err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !info.IsDir() || !strings.HasSuffix(path, gitCacheEntrySuffix) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		size, err := dirSize(path)
+		if err != nil {
+			return err
+		}
+
+		entries = append(entries, GitCacheEntry{
+			Name:     strings.TrimSuffix(filepath.ToSlash(rel), gitCacheEntrySuffix),
+			Path:     path,
+			Size:     size,
+			LastUsed: info.ModTime(),
+		})
+		return filepath.SkipDir
+	}); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, nil
+}
+
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.Walk(dir, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// remove deletes the given entry once no process uses it anymore. If wait is false and the entry is
+// currently in use, the entry is left untouched and false is returned.
+func (c *gitCache) remove(entry GitCacheEntry, wait bool) (bool, error) {
+	lock := flock.New(c.useLockPath(entry.Name))
+	if wait {
+		if err := lock.Lock(); err != nil {
+			return false, err
+		}
+	} else {
+		ok, err := lock.TryLock()
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	if err := os.RemoveAll(entry.Path); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *gitCache) Prune(maxAge time.Duration) ([]GitCacheEntry, error) {
+	entries, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		pruned []GitCacheEntry
+		limit  = time.Now().Add(-maxAge)
+	)
+	for _, entry := range entries {
+		if entry.LastUsed.After(limit) {
+			continue
+		}
+
+		ok, err := c.remove(entry, false)
+		if err != nil {
+			return pruned, fmt.Errorf("could not prune %s: %w", entry.Name, err)
+		}
+		if ok {
+			pruned = append(pruned, entry)
+		}
+	}
+	return pruned, nil
+}
+
+func (c *gitCache) Clear() error {
+	entries, err := c.List()
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if _, err := c.remove(entry, true); err != nil {
+			return fmt.Errorf("could not remove %s: %w", entry.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gem
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+func TestGitCachePrunesReferencesRemovedUpstream(t *testing.T) {
+	dir, repo := initTestRepository(t)
+	hash := commitTestFiles(t, dir, repo, map[string]string{"README.md": "test\n"})
+	for _, name := range []string{"v1.0.0", "v1.1.0"} {
+		if _, err := repo.CreateTag(name, hash, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("feature"), hash)); err != nil {
+		t.Fatal(err)
+	}
+
+	cache := NewGitCache(t.TempDir(), NoAuth)
+	defer func() { _ = cache.Close() }()
+	versions := func() []string {
+		repository, err := cache.Repository("file://" + dir)
+		if err != nil {
+			t.Fatalf("Repository: %v", err)
+		}
+		versions, err := repository.Versions()
+		if err != nil {
+			t.Fatalf("Versions: %v", err)
+		}
+		var names []string
+		for _, version := range versions {
+			names = append(names, version.Name)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	if got, want := versions(), []string{"v1.0.0", "v1.1.0"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("versions are %v, want %v", got, want)
+	}
+
+	if err := repo.DeleteTag("v1.1.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Storer.RemoveReference(plumbing.NewBranchReferenceName("feature")); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := versions(), []string{"v1.0.0"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("versions after deleting a tag upstream are %v, want %v", got, want)
+	}
+
+	repository, err := cache.Repository("file://" + dir)
+	if err != nil {
+		t.Fatalf("Repository: %v", err)
+	}
+	if _, err := repository.Branch("feature"); err == nil {
+		t.Fatal("branch deleted upstream is still resolved")
+	}
+	if got, err := repository.Branch("master"); err != nil || got != hash.String() {
+		t.Fatalf("Branch(master) returned %s, %v, want %s", got, err, hash)
+	}
+
+	// Renaming the default branch upstream removes the one HEAD of the cached clone points to.
+	main := plumbing.NewBranchReferenceName("main")
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(main, hash)); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, main)); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Storer.RemoveReference(plumbing.Master); err != nil {
+		t.Fatal(err)
+	}
+	if repository, err = cache.Repository("file://" + dir); err != nil {
+		t.Fatalf("Repository after renaming the default branch: %v", err)
+	}
+	if got, err := repository.Latest(); err != nil || got != hash.String() {
+		t.Fatalf("Latest returned %s, %v, want %s", got, err, hash)
+	}
+	if _, err := repository.Branch("master"); err == nil {
+		t.Fatal("default branch deleted upstream is still resolved")
+	}
+}
@@ -15,11 +15,13 @@
 package gem
 
 import (
+	"fmt"
 	gardencoreinstall "github.com/gardener/gardener/pkg/apis/core/install"
 	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
 	"io"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer/json"
 	"k8s.io/apimachinery/pkg/runtime/serializer/versioning"
 	"k8s.io/apimachinery/pkg/util/yaml"
@@ -31,43 +33,55 @@ func init() {
 	scheme := runtime.NewScheme()
 	gardencoreinstall.Install(scheme)
 	serializer := json.NewYAMLSerializer(json.DefaultMetaFactory, scheme, scheme)
+	// Objects of API versions that are not part of the vendored API types, e.g. ControllerDeployments of
+	// `core.gardener.cloud/v1`, are encoded as they are.
 	GardenCoreCodec = versioning.NewDefaultingCodecForScheme(
 		scheme,
 		serializer,
 		serializer,
-		gardencorev1beta1.SchemeGroupVersion,
+		schema.GroupVersions{gardencorev1beta1.SchemeGroupVersion, {Group: gardencorev1beta1.SchemeGroupVersion.Group, Version: "v1"}},
 		gardencorev1beta1.SchemeGroupVersion)
 }
 
-func LoadControllerRegistration(r io.Reader) ([]runtime.Object, error) {
-	var list []runtime.Object
+// decodeObjects decodes all documents of the given YAML stream. Empty documents, e.g. consisting only of
+// comments, are skipped. Errors are annotated with the (1-based) index of the failing document.
+func decodeObjects(r io.Reader) ([]runtime.Object, error) {
+	list := []runtime.Object{}
 	d := yaml.NewYAMLToJSONDecoder(r)
+	for i := 1; ; i++ {
+		ext := runtime.RawExtension{}
+		if err := d.Decode(&ext); err != nil {
+			if err == io.EOF {
+				return list, nil
+			}
+			return nil, fmt.Errorf("could not decode document %d: %w", i, err)
+		}
+		if len(ext.Raw) == 0 || string(ext.Raw) == "null" {
+			continue
+		}
 
-	ext := runtime.RawExtension{}
-	err := d.Decode(&ext)
-	if err != nil {
-		return nil, err
-	}
-	obj, _, err := unstructured.UnstructuredJSONScheme.Decode(ext.Raw, nil, nil)
-	if err != nil {
-		return nil, err
-	}
-	list = append(list, obj)
-
-	err = d.Decode(&ext)
-	if err != nil {
-		if err == io.EOF {
-			return list, nil // compatibility mode: only one object is present in the controller-registration.yaml
-		} else {
-			return nil, err
+		obj, _, err := unstructured.UnstructuredJSONScheme.Decode(ext.Raw, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode document %d: %w", i, err)
 		}
+		list = append(list, obj)
 	}
+}
 
-	obj, _, err = unstructured.UnstructuredJSONScheme.Decode(ext.Raw, nil, nil)
+// LoadControllerRegistration loads all objects of a controller registration file, e.g. a ControllerRegistration
+// and a ControllerDeployment. The file has to contain at least one object.
+func LoadControllerRegistration(r io.Reader) ([]runtime.Object, error) {
+	list, err := decodeObjects(r)
 	if err != nil {
 		return nil, err
 	}
-	list = append(list, obj)
-
+	if len(list) == 0 {
+		return nil, fmt.Errorf("no objects found")
+	}
 	return list, nil
 }
+
+// LoadControllerRegistrations loads all registrations of a file written by Fetch.
+func LoadControllerRegistrations(r io.Reader) ([]runtime.Object, error) {
+	return decodeObjects(r)
+}
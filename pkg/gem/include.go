@@ -0,0 +1,213 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gem
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+
+	gemapi "github.com/gardener/gem/pkg/gem/api"
+)
+
+// includeSource is the location of a requirements file. Includes without a repository are resolved relative to it.
+type includeSource struct {
+	// repository and hash are empty for local files.
+	repository string
+	hash       string
+	filename   string
+}
+
+func (s *includeSource) String() string {
+	if s.repository == "" {
+		return s.filename
+	}
+	return fmt.Sprintf("%s@%s:%s", s.repository, s.hash, s.filename)
+}
+
+type includeResolver struct {
+	registry RepositoryRegistry
+	// stack contains the files that are currently being resolved, to detect cycles.
+	stack []string
+}
+
+// source returns the location of the given include of a file at the given source.
+func (r *includeResolver) source(ctx context.Context, parent *includeSource, include *gemapi.Include) (*includeSource, error) {
+	switch {
+	case include.Repository != "":
+		repository, err := r.registry.RepositoryContext(ctx, include.Repository)
+		if err != nil {
+			return nil, err
+		}
+
+		hash, err := repository.RevisionContext(ctx, include.Revision)
+		if err != nil {
+			return nil, err
+		}
+		return &includeSource{repository: include.Repository, hash: hash, filename: path.Clean(include.Path)}, nil
+	case parent.repository != "":
+		return &includeSource{repository: parent.repository, hash: parent.hash, filename: path.Join(path.Dir(parent.filename), include.Path)}, nil
+	default:
+		filename := include.Path
+		if !filepath.IsAbs(filename) {
+			filename = filepath.Join(filepath.Dir(parent.filename), filename)
+		}
+
+		filename, err := filepath.Abs(filename)
+		if err != nil {
+			return nil, err
+		}
+		return &includeSource{filename: filename}, nil
+	}
+}
+
+func (r *includeResolver) load(ctx context.Context, source *includeSource) (*gemapi.Requirements, error) {
+	if source.repository == "" {
+		return LoadRequirementsFromFile(source.filename)
+	}
+
+	repository, err := r.registry.RepositoryContext(ctx, source.repository)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := repository.FileContext(ctx, source.hash, source.filename)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return LoadRequirements(data)
+}
+
+// resolve returns the given requirements of the given source merged with all of their (transitive) includes.
+func (r *includeResolver) resolve(ctx context.Context, source *includeSource, requirements *gemapi.Requirements) (*gemapi.Requirements, error) {
+	key := source.String()
+	for i, other := range r.stack {
+		if other == key {
+			return nil, fmt.Errorf("include cycle: %s -> %s", strings.Join(r.stack[i:], " -> "), key)
+		}
+	}
+	r.stack = append(r.stack, key)
+	defer func() { r.stack = r.stack[:len(r.stack)-1] }()
+
+	out := &gemapi.Requirements{
+		TypeMeta:     requirements.TypeMeta,
+		Requirements: make(map[gemapi.ModuleKey]*gemapi.Requirement),
+	}
+	for i := range requirements.Includes {
+		include := &requirements.Includes[i]
+
+		child, err := r.source(ctx, source, include)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve include %v of %s: %w", include, key, err)
+		}
+
+		included, err := r.load(ctx, child)
+		if err != nil {
+			return nil, fmt.Errorf("could not load include %v of %s: %w", include, key, err)
+		}
+
+		resolved, err := r.resolve(ctx, child, included)
+		if err != nil {
+			return nil, err
+		}
+		mergeRequirements(out, resolved)
+	}
+
+	own := requirements.DeepCopy()
+	own.Includes = nil
+	applyPrereleasePolicy(own)
+	mergeRequirements(out, own)
+	out.Prereleases = requirements.Prereleases
+	return out, nil
+}
+
+// applyPrereleasePolicy sets the prerelease policy of the given requirements on all of their version requirements
+// and the ones of their profiles that do not specify one.
+func applyPrereleasePolicy(requirements *gemapi.Requirements) {
+	if requirements.Prereleases == "" {
+		return
+	}
+
+	apply := func(reqs map[gemapi.ModuleKey]*gemapi.Requirement) {
+		for _, requirement := range reqs {
+			if requirement.Target.Type == gemapi.Version && requirement.Target.Prereleases == "" {
+				requirement.Target.Prereleases = requirements.Prereleases
+			}
+		}
+	}
+	apply(requirements.Requirements)
+	for _, profile := range requirements.Profiles {
+		apply(profile.Requirements)
+	}
+}
+
+// mergeRequirements merges src into dst. The requirements and profiles of src replace the ones of dst with the
+// same module key or name, the allowed overlaps are combined and the Gardener version of src replaces the one of
+// dst, if set.
+func mergeRequirements(dst, src *gemapi.Requirements) {
+	if src.GardenerVersion != "" {
+		dst.GardenerVersion = src.GardenerVersion
+	}
+
+	for moduleKey, requirement := range src.Requirements {
+		dst.Requirements[moduleKey] = requirement
+	}
+
+	allowed := make(map[gemapi.ResourceOverlap]bool, len(dst.AllowedOverlaps))
+	for _, overlap := range dst.AllowedOverlaps {
+		allowed[overlap] = true
+	}
+	for _, overlap := range src.AllowedOverlaps {
+		if !allowed[overlap] {
+			allowed[overlap] = true
+			dst.AllowedOverlaps = append(dst.AllowedOverlaps, overlap)
+		}
+	}
+
+	for name, profile := range src.Profiles {
+		if dst.Profiles == nil {
+			dst.Profiles = make(map[string]*gemapi.Profile)
+		}
+		dst.Profiles[name] = profile
+	}
+}
+
+func (g *gem) ResolveIncludes(requirements *gemapi.Requirements, filename string) (*gemapi.Requirements, error) {
+	return g.ResolveIncludesContext(context.Background(), requirements, filename)
+}
+
+// ResolveIncludesContext returns the requirements merged with all of their (transitive) includes. Local includes
+// are resolved relative to the given filename of the requirements, or to the working directory if it is empty.
+func (g *gem) ResolveIncludesContext(ctx context.Context, requirements *gemapi.Requirements, filename string) (*gemapi.Requirements, error) {
+	if filename == "" {
+		filename = "requirements"
+	}
+
+	filename, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &includeResolver{registry: g.registry}
+	return r.resolve(ctx, &includeSource{filename: filename}, requirements)
+}
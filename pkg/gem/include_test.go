@@ -0,0 +1,280 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gem
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeRegistry returns the given repositories by name.
+type fakeRegistry map[string]Repository
+
+func (r fakeRegistry) Repository(name string) (Repository, error) {
+	return r.RepositoryContext(context.Background(), name)
+}
+
+func (r fakeRegistry) RepositoryContext(ctx context.Context, name string) (Repository, error) {
+	repository, ok := r[name]
+	if !ok {
+		return nil, fmt.Errorf("repository %s not found", name)
+	}
+	return repository, nil
+}
+
+const requirementsHeader = "apiVersion: gem.gardener.cloud/v1alpha2\nkind: Requirements\n"
+
+func TestResolveIncludes(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		// files are the local files relative to a temporary directory, main.yaml is resolved.
+		files map[string]string
+		// repositoryFiles are the files of the repository `example.com/platform` by revision and path.
+		repositoryFiles map[string]map[string]string
+		want            map[string]string
+		err             string
+	}{
+		{
+			name: "later includes and the including file take precedence",
+			files: map[string]string{
+				"main.yaml": `includes:
+- path: base.yaml
+- path: team/team.yaml
+requirements:
+- repository: example.com/c
+  version: 3.0.0
+`,
+				"base.yaml": `requirements:
+- repository: example.com/a
+  version: 1.0.0
+- repository: example.com/b
+  version: 1.0.0
+`,
+				"team/team.yaml": `requirements:
+- repository: example.com/b
+  version: 2.0.0
+- repository: example.com/c
+  version: 2.0.0
+`,
+			},
+			want: map[string]string{"example.com/a": "1.0.0", "example.com/b": "2.0.0", "example.com/c": "3.0.0"},
+		},
+		{
+			name: "includes of files in repositories are read from the same revision",
+			files: map[string]string{
+				"main.yaml": `includes:
+- repository: example.com/platform
+  revision: 0123456789abcdef0123456789abcdef01234567
+  path: extensions/requirements.yaml
+`,
+			},
+			repositoryFiles: map[string]map[string]string{
+				"0123456789abcdef0123456789abcdef01234567": {
+					"extensions/requirements.yaml": requirementsHeader + `includes:
+- path: base.yaml
+requirements:
+- repository: example.com/b
+  version: 2.0.0
+`,
+					"extensions/base.yaml": requirementsHeader + `requirements:
+- repository: example.com/a
+  version: 1.0.0
+- repository: example.com/b
+  version: 1.0.0
+`,
+				},
+			},
+			want: map[string]string{"example.com/a": "1.0.0", "example.com/b": "2.0.0"},
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"main.yaml":  "includes:\n- path: a.yaml\n",
+				"a.yaml":     "includes:\n- path: sub/b.yaml\n",
+				"sub/b.yaml": "includes:\n- path: ../a.yaml\n",
+			},
+			err: "include cycle: DIR/a.yaml -> DIR/sub/b.yaml -> DIR/a.yaml",
+		},
+		{
+			name: "self include",
+			files: map[string]string{
+				"main.yaml": "includes:\n- path: main.yaml\n",
+			},
+			err: "include cycle: DIR/main.yaml -> DIR/main.yaml",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, data := range tc.files {
+				filename := filepath.Join(dir, name)
+				if err := writeFile(filename, []byte(requirementsHeader+data)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			registry := fakeRegistry{"example.com/platform": &fakeRepository{files: tc.repositoryFiles}}
+			g := New(DefaultLogger, registry, nil)
+
+			filename := filepath.Join(dir, "main.yaml")
+			data, err := ioutil.ReadFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			requirements, err := LoadRequirements(data)
+			if err != nil {
+				t.Fatalf("LoadRequirements: %v", err)
+			}
+
+			resolved, err := g.ResolveIncludesContext(context.Background(), requirements, filename)
+			if tc.err != "" {
+				if err == nil || err.Error() != strings.ReplaceAll(tc.err, "DIR", dir) {
+					t.Fatalf("ResolveIncludesContext returned %v, want %s", err, strings.ReplaceAll(tc.err, "DIR", dir))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveIncludesContext: %v", err)
+			}
+
+			got := make(map[string]string, len(resolved.Requirements))
+			for moduleKey, requirement := range resolved.Requirements {
+				got[moduleKey.Repository] = requirement.Target.Version
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("resolved requirements are %v, want %v", got, tc.want)
+			}
+			if len(resolved.Includes) != 0 {
+				t.Fatalf("resolved requirements still have includes %v", resolved.Includes)
+			}
+		})
+	}
+}
+
+func TestResolveIncludesProfiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.yaml": `includes:
+- path: base.yaml
+profiles:
+- name: live
+  requirements:
+  - repository: example.com/a
+    version: 3.0.0
+`,
+		"base.yaml": `requirements:
+- repository: example.com/a
+  version: 1.0.0
+profiles:
+- name: live
+  requirements:
+  - repository: example.com/a
+    version: 2.0.0
+- name: dev
+  exclude:
+  - repository: example.com/a
+`,
+	}
+	for name, data := range files {
+		if err := writeFile(filepath.Join(dir, name), []byte(requirementsHeader+data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	filename := filepath.Join(dir, "main.yaml")
+	requirements, err := LoadRequirementsFromFile(filename)
+	if err != nil {
+		t.Fatalf("LoadRequirementsFromFile: %v", err)
+	}
+	resolved, err := New(DefaultLogger, fakeRegistry{}, nil).ResolveIncludesContext(context.Background(), requirements, filename)
+	if err != nil {
+		t.Fatalf("ResolveIncludesContext: %v", err)
+	}
+
+	for _, tc := range []struct {
+		profile string
+		want    map[string]string
+	}{
+		{profile: "", want: map[string]string{"example.com/a": "1.0.0"}},
+		{profile: "live", want: map[string]string{"example.com/a": "3.0.0"}},
+		{profile: "dev", want: map[string]string{}},
+	} {
+		applied, err := ApplyProfile(resolved, tc.profile)
+		if err != nil {
+			t.Fatalf("profile %q: ApplyProfile: %v", tc.profile, err)
+		}
+		got := make(map[string]string, len(applied.Requirements))
+		for moduleKey, requirement := range applied.Requirements {
+			got[moduleKey.Repository] = requirement.Target.Version
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("profile %q: requirements are %v, want %v", tc.profile, got, tc.want)
+		}
+	}
+
+}
+
+func TestResolveIncludesGitRepository(t *testing.T) {
+	repoDir, repo := initTestRepository(t)
+	hash := commitTestFiles(t, repoDir, repo, map[string]string{
+		"extensions/requirements.yaml": requirementsHeader + "includes:\n- path: base.yaml\n",
+		"extensions/base.yaml":         requirementsHeader + "requirements:\n- repository: example.com/a\n  version: 1.0.0\n",
+	})
+	if _, err := repo.CreateTag("v1.0.0", hash, nil); err != nil {
+		t.Fatal(err)
+	}
+	g := New(DefaultLogger, fakeRegistry{"example.com/platform": NewGitRepository(repo)}, nil)
+
+	for _, tc := range []struct {
+		revision string
+		err      bool
+	}{
+		{revision: hash.String()},
+		{revision: "v1.0.0", err: true},
+		{revision: "master", err: true},
+		{revision: hash.String()[:12], err: true},
+	} {
+		requirements, err := LoadRequirements([]byte(requirementsHeader + fmt.Sprintf(`includes:
+- repository: example.com/platform
+  revision: %s
+  path: extensions/requirements.yaml
+`, tc.revision)))
+		if tc.err {
+			if err == nil || !strings.Contains(err.Error(), "full 40-character commit hash") {
+				t.Fatalf("revision %s: LoadRequirements returned %v, want an error about the commit hash", tc.revision, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("revision %s: LoadRequirements: %v", tc.revision, err)
+		}
+
+		resolved, err := g.ResolveIncludesContext(context.Background(), requirements, filepath.Join(t.TempDir(), "requirements.yaml"))
+		if err != nil {
+			t.Fatalf("revision %s: ResolveIncludesContext: %v", tc.revision, err)
+		}
+		got := make(map[string]string, len(resolved.Requirements))
+		for moduleKey, requirement := range resolved.Requirements {
+			got[moduleKey.Repository] = requirement.Target.Version
+		}
+		if want := map[string]string{"example.com/a": "1.0.0"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("revision %s: resolved requirements are %v, want %v", tc.revision, got, want)
+		}
+	}
+}
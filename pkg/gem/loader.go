@@ -22,6 +22,7 @@ import (
 	gemapilatest "github.com/gardener/gem/pkg/gem/api/latest"
 	osutil "github.com/gardener/gem/pkg/util/os"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 func writeFile(filename string, data []byte) error {
@@ -76,6 +77,16 @@ func LoadLocks(data []byte) (*gemapi.Locks, error) {
 	return locks, nil
 }
 
+// LocksVersion returns the API version the given locks are written in.
+func LocksVersion(data []byte) (schema.GroupVersion, error) {
+	_, gvk, err := gemapilatest.Codec.Decode(data, nil, &gemapi.Locks{})
+	if err != nil {
+		return schema.GroupVersion{}, err
+	}
+
+	return gvk.GroupVersion(), nil
+}
+
 func LoadLocksFromFile(filename string) (*gemapi.Locks, error) {
 	data, err := ioutil.ReadFile(filename)
 	if err != nil {
@@ -93,6 +104,11 @@ func WriteLocks(locks *gemapi.Locks) ([]byte, error) {
 	return runtime.Encode(gemapilatest.Codec, locks)
 }
 
+// WriteLocksVersion encodes the locks in the given API version.
+func WriteLocksVersion(locks *gemapi.Locks, gv schema.GroupVersion) ([]byte, error) {
+	return runtime.Encode(gemapilatest.NewCodec(gv), locks)
+}
+
 func WriteLocksToFile(locks *gemapi.Locks, filename string) error {
 	data, err := WriteLocks(locks)
 	if err != nil {
@@ -101,3 +117,48 @@ func WriteLocksToFile(locks *gemapi.Locks, filename string) error {
 
 	return writeFile(filename, data)
 }
+
+func LoadCredentials(data []byte) (*gemapi.Credentials, error) {
+	credentials := &gemapi.Credentials{}
+	if err := runtime.DecodeInto(gemapilatest.Codec, data, credentials); err != nil {
+		return nil, err
+	}
+
+	return credentials, nil
+}
+
+func LoadCredentialsFromFile(filename string) (*gemapi.Credentials, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadCredentials(data)
+}
+
+func LoadDenyList(data []byte) (*gemapi.DenyList, error) {
+	denyList := &gemapi.DenyList{}
+	if err := runtime.DecodeInto(gemapilatest.Codec, data, denyList); err != nil {
+		return nil, err
+	}
+
+	return denyList, nil
+}
+
+func LoadDenyListFromFile(filename string) (*gemapi.DenyList, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadDenyList(data)
+}
+
+func LoadConstraints(data []byte) (*gemapi.Constraints, error) {
+	constraints := &gemapi.Constraints{}
+	if err := runtime.DecodeInto(gemapilatest.Codec, data, constraints); err != nil {
+		return nil, err
+	}
+
+	return constraints, nil
+}
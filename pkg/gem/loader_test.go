@@ -0,0 +1,156 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gem
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+
+	gemapi "github.com/gardener/gem/pkg/gem/api"
+	gemapilatest "github.com/gardener/gem/pkg/gem/api/latest"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestWriteLocksVersionKeepsVersion(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		data       string
+		apiVersion string
+	}{
+		{
+			name: "v1alpha1",
+			data: `apiVersion: gem.gardener.cloud/v1alpha1
+kind: Locks
+locks:
+- name: github.com/gardener/gardener-extensions/controllers/provider-aws
+  hash: 9c0d8c5801c3e7a8165b8983eeea1ca95243aecc
+  version: ^1.0.0
+  resolved:
+    version: v1.2.0
+`,
+			apiVersion: "gem.gardener.cloud/v1alpha1",
+		},
+		{
+			name: "v1alpha2",
+			data: `apiVersion: gem.gardener.cloud/v1alpha2
+kind: Locks
+locks:
+- repository: github.com/gardener/gardener-extensions
+  submodule: controllers/provider-aws
+  hash: 9c0d8c5801c3e7a8165b8983eeea1ca95243aecc
+  version: ^1.0.0
+  resolved:
+    version: v1.2.0
+`,
+			apiVersion: "gem.gardener.cloud/v1alpha2",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			gv, err := LocksVersion([]byte(tc.data))
+			if err != nil {
+				t.Fatalf("LocksVersion: %v", err)
+			}
+			if gv.String() != tc.apiVersion {
+				t.Fatalf("LocksVersion = %s, want %s", gv, tc.apiVersion)
+			}
+
+			locks, err := LoadLocks([]byte(tc.data))
+			if err != nil {
+				t.Fatalf("LoadLocks: %v", err)
+			}
+			data, err := WriteLocksVersion(locks, gv)
+			if err != nil {
+				t.Fatalf("WriteLocksVersion: %v", err)
+			}
+			if !bytes.Contains(data, []byte("apiVersion: "+tc.apiVersion+"\n")) {
+				t.Fatalf("locks were not written in %s:\n%s", tc.apiVersion, data)
+			}
+
+			latest, err := WriteLocksVersion(locks, gemapilatest.GroupVersion)
+			if err != nil {
+				t.Fatalf("WriteLocksVersion: %v", err)
+			}
+			reloaded, err := LoadLocks(data)
+			if err != nil {
+				t.Fatalf("LoadLocks: %v", err)
+			}
+			reencoded, err := WriteLocksVersion(reloaded, gemapilatest.GroupVersion)
+			if err != nil {
+				t.Fatalf("WriteLocksVersion: %v", err)
+			}
+			if !bytes.Equal(latest, reencoded) {
+				t.Fatalf("locks changed when written in %s:\n%s\nwant:\n%s", tc.apiVersion, reencoded, latest)
+			}
+		})
+	}
+}
+
+func TestLoadRequirementsExclude(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		exclude string
+		want    []gemapi.ExcludedVersion
+		written []string
+	}{
+		{
+			name:    "plain versions",
+			exclude: "\n  - 1.2.0\n  - 1.3.0",
+			want:    []gemapi.ExcludedVersion{{Version: "1.2.0"}, {Version: "1.3.0"}},
+			written: []string{"- 1.2.0\n", "- 1.3.0\n"},
+		},
+		{
+			name:    "versions with reason",
+			exclude: "\n  - version: 1.2.0\n    reason: broken\n  - 1.3.0",
+			want:    []gemapi.ExcludedVersion{{Version: "1.2.0", Reason: "broken"}, {Version: "1.3.0"}},
+			written: []string{"- reason: broken\n", "version: 1.2.0\n", "- 1.3.0\n"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, apiVersion := range []string{"v1alpha1", "v1alpha2"} {
+				name := "repository: github.com/gardener/gardener-extension-provider-aws"
+				if apiVersion == "v1alpha1" {
+					name = "name: github.com/gardener/gardener-extension-provider-aws"
+				}
+				data := fmt.Sprintf("apiVersion: gem.gardener.cloud/%s\nkind: Requirements\nrequirements:\n- %s\n  version: ^1.0.0\n  exclude:%s\n", apiVersion, name, tc.exclude)
+
+				requirements, err := LoadRequirements([]byte(data))
+				if err != nil {
+					t.Fatalf("%s: LoadRequirements: %v", apiVersion, err)
+				}
+				moduleKey := gemapi.ModuleKey{Repository: "github.com/gardener/gardener-extension-provider-aws"}
+				requirement, ok := requirements.Requirements[moduleKey]
+				if !ok {
+					t.Fatalf("%s: %s is not required", apiVersion, &moduleKey)
+				}
+				if !reflect.DeepEqual(requirement.Target.Exclude, tc.want) {
+					t.Fatalf("%s: excluded versions are %v, want %v", apiVersion, requirement.Target.Exclude, tc.want)
+				}
+
+				written, err := runtime.Encode(gemapilatest.NewCodec(schema.GroupVersion{Group: gemapilatest.GroupVersion.Group, Version: apiVersion}), requirements)
+				if err != nil {
+					t.Fatalf("%s: encode: %v", apiVersion, err)
+				}
+				for _, line := range tc.written {
+					if !bytes.Contains(written, []byte(line)) {
+						t.Fatalf("%s: written requirements do not contain %q:\n%s", apiVersion, line, written)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gem
+
+import (
+	"fmt"
+	"sort"
+
+	gemapi "github.com/gardener/gem/pkg/gem/api"
+)
+
+// ProfileNames returns the names of all profiles of the given requirements, sorted by name.
+func ProfileNames(requirements *gemapi.Requirements) []string {
+	names := make([]string, 0, len(requirements.Profiles))
+	for name := range requirements.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyProfile returns the requirements of the profile with the given name: the base requirements without the
+// excluded modules, overlaid with the requirements and the Gardener version of the profile. The returned
+// requirements have no profiles. If name is empty, the base requirements are returned.
+func ApplyProfile(requirements *gemapi.Requirements, name string) (*gemapi.Requirements, error) {
+	out := requirements.DeepCopy()
+	out.Profiles = nil
+	if name == "" {
+		return out, nil
+	}
+
+	profile, ok := requirements.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %q, available profiles: %v", name, ProfileNames(requirements))
+	}
+
+	for i := range profile.Exclude {
+		moduleKey := profile.Exclude[i]
+		if _, ok := out.Requirements[moduleKey]; !ok {
+			return nil, fmt.Errorf("profile %q excludes extension %q which is not required", name, &moduleKey)
+		}
+		delete(out.Requirements, moduleKey)
+	}
+
+	if out.Requirements == nil {
+		out.Requirements = make(map[gemapi.ModuleKey]*gemapi.Requirement)
+	}
+	for moduleKey, requirement := range profile.Requirements {
+		r := *requirement
+		out.Requirements[moduleKey] = &r
+	}
+	out.AllowedOverlaps = append(out.AllowedOverlaps, profile.AllowedOverlaps...)
+	if profile.GardenerVersion != "" {
+		out.GardenerVersion = profile.GardenerVersion
+	}
+	return out, nil
+}
@@ -0,0 +1,327 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gem
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	gemioutil "github.com/gardener/gem/pkg/util/io"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/protocol/packp"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/client"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
+)
+
+type remoteRepositoryRegistry struct {
+	auth     AuthProvider
+	fallback RepositoryRegistry
+}
+
+// NewRemoteRepositoryRegistry creates a new RepositoryRegistry whose repositories resolve references
+// by listing the references of the remote (like `git ls-remote`) instead of cloning it.
+// Objects are only fetched if they are needed and only the commit of the reference they are
+// requested for. If an object is not reachable via an advertised reference, the repository
+// of the fallback registry is used.
+func NewRemoteRepositoryRegistry(auth AuthProvider, fallback RepositoryRegistry) RepositoryRegistry {
+	return &remoteRepositoryRegistry{auth, fallback}
+}
+
+func (r *remoteRepositoryRegistry) Repository(name string) (Repository, error) {
+	return r.RepositoryContext(context.Background(), name)
+}
+
+func (r *remoteRepositoryRegistry) RepositoryContext(ctx context.Context, name string) (Repository, error) {
+	u, err := parseRepositoryURL(name)
+	if err != nil {
+		return nil, err
+	}
+
+	auth, err := r.auth.Auth(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := listRemote(ctx, u.String(), auth)
+	if err != nil {
+		return nil, err
+	}
+
+	return &remoteRepository{
+		url:     u.String(),
+		auth:    auth,
+		refs:    refs,
+		objects: newCallCache(),
+		fallback: func(ctx context.Context) (Repository, error) {
+			return r.fallback.RepositoryContext(ctx, name)
+		},
+	}, nil
+}
+
+// listRemote lists the references advertised by the remote. go-git does not support
+// canceling the advertisement, so the session is closed once the context is done.
+func listRemote(ctx context.Context, url string, auth transport.AuthMethod) (*packp.AdvRefs, error) {
+	endpoint, err := transport.NewEndpoint(url)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := client.NewClient(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := c.NewUploadPackSession(endpoint, auth)
+	if err != nil {
+		return nil, err
+	}
+	defer gemioutil.CloseSilently(session)
+
+	type result struct {
+		refs *packp.AdvRefs
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		refs, err := session.AdvertisedReferences()
+		done <- result{refs, err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.refs, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+type remoteRepository struct {
+	url      string
+	auth     transport.AuthMethod
+	refs     *packp.AdvRefs
+	objects  *callCache
+	fallback func(ctx context.Context) (Repository, error)
+}
+
+type fullRepositoryKey struct{}
+
+func (r *remoteRepository) fullRepository(ctx context.Context) (Repository, error) {
+	full, err := r.objects.do(ctx, fullRepositoryKey{}, func(ctx context.Context) (interface{}, error) {
+		return r.fallback(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return full.(Repository), nil
+}
+
+func (r *remoteRepository) Revision(name string) (string, error) {
+	return r.RevisionContext(context.Background(), name)
+}
+
+func (r *remoteRepository) RevisionContext(ctx context.Context, name string) (string, error) {
+	full, err := r.fullRepository(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return full.RevisionContext(ctx, name)
+}
+
+func (r *remoteRepository) Branch(name string) (string, error) {
+	return r.BranchContext(context.Background(), name)
+}
+
+func (r *remoteRepository) BranchContext(_ context.Context, name string) (string, error) {
+	hash, ok := r.refs.References[plumbing.NewBranchReferenceName(name).String()]
+	if !ok {
+		return "", plumbing.ErrReferenceNotFound
+	}
+
+	return hash.String(), nil
+}
+
+func (r *remoteRepository) Versions() ([]RepositoryVersion, error) {
+	return r.VersionsContext(context.Background())
+}
+
+func (r *remoteRepository) VersionsContext(context.Context) ([]RepositoryVersion, error) {
+	var versions []RepositoryVersion
+	for refName, hash := range r.refs.References {
+		name := plumbing.ReferenceName(refName)
+		if !name.IsTag() {
+			continue
+		}
+
+		prefix, v, err := parseVersionTag(name.Short())
+		if err != nil {
+			continue
+		}
+
+		version := RepositoryVersion{
+			Version: *v,
+			Name:    name.Short(),
+			Prefix:  prefix,
+			Hash:    hash.String(),
+		}
+		if peeled, ok := r.refs.Peeled[refName]; ok {
+			version.Hash = peeled.String()
+			version.TagHash = hash.String()
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, nil
+}
+
+func (r *remoteRepository) Latest() (string, error) {
+	return r.LatestContext(context.Background())
+}
+
+func (r *remoteRepository) LatestContext(context.Context) (string, error) {
+	if r.refs.Head == nil {
+		return "", plumbing.ErrReferenceNotFound
+	}
+
+	return r.refs.Head.String(), nil
+}
+
+// referenceNameFor returns the name of an advertised reference pointing to the given hash,
+// either directly or via an annotated tag.
+func (r *remoteRepository) referenceNameFor(hash plumbing.Hash) (plumbing.ReferenceName, bool) {
+	var names []string
+	for name, h := range r.refs.References {
+		if h == hash || r.refs.Peeled[name] == hash {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "", false
+	}
+
+	sort.Strings(names)
+	return plumbing.ReferenceName(names[0]), true
+}
+
+// fetchReference does a shallow fetch of the given reference into a new in-memory repository. If the reference
+// no longer points to the given advertised hash, e.g. because it was pushed to in the meantime, the full
+// repository is returned instead.
+func (r *remoteRepository) fetchReference(ctx context.Context, name plumbing.ReferenceName, hash plumbing.Hash) (Repository, error) {
+	repo, err := git.Init(memory.NewStorage(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	remote, err := repo.CreateRemote(&config.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{r.url},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := remote.FetchContext(ctx, &git.FetchOptions{
+		RefSpecs: []config.RefSpec{config.RefSpec(fmt.Sprintf("+%s:%s", name, name))},
+		Auth:     r.auth,
+		Depth:    1,
+		Tags:     git.NoTags,
+	}); err != nil && err != git.NoErrAlreadyUpToDate {
+		return nil, fmt.Errorf("could not fetch %s from %s: %w", name, r.url, err)
+	}
+
+	ref, err := repo.Reference(name, false)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch %s from %s: %w", name, r.url, err)
+	}
+	if ref.Hash() != hash {
+		return r.fullRepository(ctx)
+	}
+
+	return NewGitRepository(repo), nil
+}
+
+// repositoryFor returns a Repository that contains the object with the given hash.
+func (r *remoteRepository) repositoryFor(ctx context.Context, hash string) (Repository, error) {
+	h := plumbing.NewHash(hash)
+	name, ok := r.referenceNameFor(h)
+	if !ok {
+		return r.fullRepository(ctx)
+	}
+
+	repo, err := r.objects.do(ctx, h, func(ctx context.Context) (interface{}, error) {
+		return r.fetchReference(ctx, name, r.refs.References[name.String()])
+	})
+	if err != nil {
+		return nil, err
+	}
+	return repo.(Repository), nil
+}
+
+func (r *remoteRepository) File(hash, path string) (io.Reader, error) {
+	return r.FileContext(context.Background(), hash, path)
+}
+
+func (r *remoteRepository) FileContext(ctx context.Context, hash, path string) (io.Reader, error) {
+	repo, err := r.repositoryFor(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.FileContext(ctx, hash, path)
+}
+
+func (r *remoteRepository) HasFile(hash, path string) (bool, error) {
+	return r.HasFileContext(context.Background(), hash, path)
+}
+
+func (r *remoteRepository) HasFileContext(ctx context.Context, hash, path string) (bool, error) {
+	repo, err := r.repositoryFor(ctx, hash)
+	if err != nil {
+		return false, err
+	}
+
+	return repo.HasFileContext(ctx, hash, path)
+}
+
+func (r *remoteRepository) Signature(hash string) (string, []byte, error) {
+	return r.SignatureContext(context.Background(), hash)
+}
+
+func (r *remoteRepository) SignatureContext(ctx context.Context, hash string) (string, []byte, error) {
+	repo, err := r.repositoryFor(ctx, hash)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return repo.SignatureContext(ctx, hash)
+}
+
+func (r *remoteRepository) ObjectInfo(hash string) (*ObjectInfo, error) {
+	return r.ObjectInfoContext(context.Background(), hash)
+}
+
+func (r *remoteRepository) ObjectInfoContext(ctx context.Context, hash string) (*ObjectInfo, error) {
+	repo, err := r.repositoryFor(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.ObjectInfoContext(ctx, hash)
+}
@@ -0,0 +1,46 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gem
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func TestRemoteRepositoryFallsBackIfReferenceMoved(t *testing.T) {
+	dir, repo := initTestRepository(t)
+	first := commitTestFiles(t, dir, repo, map[string]string{"README.md": "first\n"})
+
+	registry := NewRemoteRepositoryRegistry(NoAuth, GitRepositoryRegistry)
+	repository, err := registry.Repository("file://" + dir)
+	if err != nil {
+		t.Fatalf("Repository: %v", err)
+	}
+
+	// The branch moves after it was listed, a shallow fetch of it does not contain the listed commit anymore.
+	commitTestFiles(t, dir, repo, map[string]string{"README.md": "second\n"})
+
+	reader, err := repository.File(first.String(), "README.md")
+	if err != nil {
+		t.Fatalf("File: %v", err)
+	}
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "first\n" {
+		t.Fatalf("File returned %q, want %q", data, "first\n")
+	}
+}
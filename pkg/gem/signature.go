@@ -0,0 +1,210 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gem
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/gardener/gem/pkg/util/sshsig"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/ssh"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+const (
+	pgpSignatureHeader = "-----BEGIN PGP SIGNATURE-----"
+	sshSignatureHeader = "-----BEGIN SSH SIGNATURE-----"
+
+	// sshSignatureNamespace is the namespace git uses for SSH signatures.
+	sshSignatureNamespace = "git"
+)
+
+// parseSignedObject splits the raw data of a commit or tag object into its signature and the signed payload.
+// The signature is empty if the object is not signed.
+func parseSignedObject(objectType plumbing.ObjectType, data []byte) (string, []byte) {
+	switch objectType {
+	case plumbing.CommitObject:
+		return parseSignedCommit(data)
+	case plumbing.TagObject:
+		return parseSignedTag(data)
+	default:
+		return "", data
+	}
+}
+
+// parseSignedCommit removes the `gpgsig` header, which contains the signature, from the commit headers.
+func parseSignedCommit(data []byte) (string, []byte) {
+	var (
+		signature strings.Builder
+		payload   bytes.Buffer
+		inHeaders = true
+		inSig     bool
+	)
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		if inHeaders {
+			if inSig && bytes.HasPrefix(line, []byte(" ")) {
+				signature.Write(line[1:])
+				continue
+			}
+			inSig = false
+
+			if bytes.HasPrefix(line, []byte("gpgsig ")) {
+				inSig = true
+				signature.Write(bytes.TrimPrefix(line, []byte("gpgsig ")))
+				continue
+			}
+			if len(bytes.TrimSpace(line)) == 0 {
+				inHeaders = false
+			}
+		}
+		payload.Write(line)
+	}
+	return signature.String(), payload.Bytes()
+}
+
+// parseSignedTag splits the signature, which is appended to the message, from the tag.
+func parseSignedTag(data []byte) (string, []byte) {
+	idx := -1
+	for _, header := range []string{pgpSignatureHeader, sshSignatureHeader} {
+		if i := bytes.Index(data, []byte("\n"+header)); i >= 0 && (idx < 0 || i < idx) {
+			idx = i
+		}
+	}
+	if idx < 0 {
+		return "", data
+	}
+	return string(data[idx+1:]), data[:idx+1]
+}
+
+// AllowedSigner is a public SSH key that is trusted to sign on behalf of the given principals.
+type AllowedSigner struct {
+	Principals string
+	PublicKey  ssh.PublicKey
+}
+
+type keyringSignatureVerifier struct {
+	keyring        openpgp.EntityList
+	allowedSigners []AllowedSigner
+}
+
+// NewKeyringSignatureVerifier creates a new SignatureVerifier that trusts PGP signatures of the
+// given keyring and SSH signatures of the given allowed signers.
+func NewKeyringSignatureVerifier(keyring openpgp.EntityList, allowedSigners []AllowedSigner) SignatureVerifier {
+	return &keyringSignatureVerifier{keyring, allowedSigners}
+}
+
+func (k *keyringSignatureVerifier) Verify(signature string, payload []byte) (string, error) {
+	switch {
+	case strings.HasPrefix(signature, pgpSignatureHeader):
+		return k.verifyPGP(signature, payload)
+	case strings.HasPrefix(signature, sshSignatureHeader):
+		return k.verifySSH(signature, payload)
+	default:
+		return "", fmt.Errorf("unsupported signature format")
+	}
+}
+
+func (k *keyringSignatureVerifier) verifyPGP(signature string, payload []byte) (string, error) {
+	entity, err := openpgp.CheckArmoredDetachedSignature(k.keyring, bytes.NewReader(payload), strings.NewReader(signature))
+	if err != nil {
+		return "", fmt.Errorf("PGP signature is invalid or not made by a trusted key: %w", err)
+	}
+
+	var name string
+	for identity := range entity.Identities {
+		if name == "" || identity < name {
+			name = identity
+		}
+	}
+	return fmt.Sprintf("%s (pgp:%016X)", name, entity.PrimaryKey.KeyId), nil
+}
+
+func (k *keyringSignatureVerifier) verifySSH(signature string, payload []byte) (string, error) {
+	sig, err := sshsig.ParseArmored([]byte(signature))
+	if err != nil {
+		return "", err
+	}
+
+	var signer *AllowedSigner
+	for i := range k.allowedSigners {
+		if bytes.Equal(k.allowedSigners[i].PublicKey.Marshal(), sig.PublicKey.Marshal()) {
+			signer = &k.allowedSigners[i]
+			break
+		}
+	}
+	if signer == nil {
+		return "", fmt.Errorf("SSH signature is made by untrusted key %s", sig.Fingerprint())
+	}
+
+	if err := sig.Verify(sshSignatureNamespace, payload); err != nil {
+		return "", fmt.Errorf("SSH signature is invalid: %w", err)
+	}
+	return fmt.Sprintf("%s (ssh:%s)", signer.Principals, sig.Fingerprint()), nil
+}
+
+// LoadKeyringFromFile loads a PGP keyring, either armored or binary, e.g. as exported by `gpg --export --armor`.
+func LoadKeyringFromFile(filename string) (openpgp.EntityList, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.Contains(data, []byte("-----BEGIN PGP PUBLIC KEY BLOCK-----")) {
+		return openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+	}
+	return openpgp.ReadKeyRing(bytes.NewReader(data))
+}
+
+// LoadAllowedSigners loads SSH signers in the format of git's `gpg.ssh.allowedSignersFile`, i.e.
+// lines of comma-separated principals followed by options and a public key like in `authorized_keys`.
+func LoadAllowedSigners(data []byte) ([]AllowedSigner, error) {
+	var signers []AllowedSigner
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.SplitN(line, " ", 2)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: missing public key", lineNumber)
+		}
+
+		publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(fields[1]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		signers = append(signers, AllowedSigner{Principals: fields[0], PublicKey: publicKey})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return signers, nil
+}
+
+// LoadAllowedSignersFromFile loads the allowed SSH signers from the given file.
+func LoadAllowedSignersFromFile(filename string) ([]AllowedSigner, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadAllowedSigners(data)
+}
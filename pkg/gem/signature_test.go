@@ -0,0 +1,218 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gem
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/gardener/gem/pkg/util/sshsig"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/packet"
+	"golang.org/x/crypto/ssh"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+func newPGPEntity(t *testing.T, name string) *openpgp.Entity {
+	t.Helper()
+	entity, err := openpgp.NewEntity(name, "", name+"@example.com", &packet.Config{RSABits: 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entity
+}
+
+func signPGP(t *testing.T, entity *openpgp.Entity, payload []byte) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&buf, entity, bytes.NewReader(payload), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func newSSHSigner(t *testing.T) ssh.Signer {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+// signSSH signs the payload like `ssh-keygen -Y sign -n <namespace>` does.
+func signSSH(t *testing.T, signer ssh.Signer, namespace string, payload []byte) string {
+	t.Helper()
+	hash := sha512.Sum512(payload)
+	signed := append([]byte("SSHSIG"), ssh.Marshal(struct {
+		Namespace     string
+		Reserved      []byte
+		HashAlgorithm string
+		Hash          []byte
+	}{namespace, nil, "sha512", hash[:]})...)
+
+	signature, err := signer.Sign(rand.Reader, signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob := append([]byte("SSHSIG"), ssh.Marshal(struct {
+		Version       uint32
+		PublicKey     []byte
+		Namespace     string
+		Reserved      []byte
+		HashAlgorithm string
+		Signature     []byte
+	}{1, signer.PublicKey().Marshal(), namespace, nil, "sha512", ssh.Marshal(signature)})...)
+	return string(pem.EncodeToMemory(&pem.Block{Type: sshsig.PEMType, Bytes: blob}))
+}
+
+func TestKeyringSignatureVerifier(t *testing.T) {
+	var (
+		payload = []byte("object 9c0d8c5801c3e7a8165b8983eeea1ca95243aecc\ntype commit\ntag v1.0.0\n\nRelease v1.0.0\n")
+		alice   = newPGPEntity(t, "alice")
+		mallory = newPGPEntity(t, "mallory")
+		bob     = newSSHSigner(t)
+		eve     = newSSHSigner(t)
+	)
+	verifier := NewKeyringSignatureVerifier(openpgp.EntityList{alice}, []AllowedSigner{{Principals: "bob@example.com", PublicKey: bob.PublicKey()}})
+
+	for _, tc := range []struct {
+		name      string
+		signature string
+		payload   []byte
+		signer    string
+		err       string
+	}{
+		{
+			name:      "trusted PGP key",
+			signature: signPGP(t, alice, payload),
+			signer:    "alice <alice@example.com> (pgp:",
+		},
+		{
+			name:      "untrusted PGP key",
+			signature: signPGP(t, mallory, payload),
+			err:       "PGP signature is invalid or not made by a trusted key",
+		},
+		{
+			name:      "PGP signature of another payload",
+			signature: signPGP(t, alice, payload),
+			payload:   []byte("tampered"),
+			err:       "PGP signature is invalid or not made by a trusted key",
+		},
+		{
+			name:      "trusted SSH key",
+			signature: signSSH(t, bob, sshSignatureNamespace, payload),
+			signer:    "bob@example.com (ssh:" + ssh.FingerprintSHA256(bob.PublicKey()) + ")",
+		},
+		{
+			name:      "untrusted SSH key",
+			signature: signSSH(t, eve, sshSignatureNamespace, payload),
+			err:       "SSH signature is made by untrusted key " + ssh.FingerprintSHA256(eve.PublicKey()),
+		},
+		{
+			name:      "SSH signature of another namespace",
+			signature: signSSH(t, bob, "file", payload),
+			err:       "SSH signature is invalid: signature was made for namespace \"file\" instead of \"git\"",
+		},
+		{
+			name:      "SSH signature of another payload",
+			signature: signSSH(t, bob, sshSignatureNamespace, payload),
+			payload:   []byte("tampered"),
+			err:       "SSH signature is invalid",
+		},
+		{
+			name:      "unsupported format",
+			signature: "-----BEGIN SIGNED MESSAGE-----",
+			err:       "unsupported signature format",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			signedPayload := payload
+			if tc.payload != nil {
+				signedPayload = tc.payload
+			}
+
+			signer, err := verifier.Verify(tc.signature, signedPayload)
+			if tc.err != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tc.err) {
+					t.Fatalf("Verify returned %q, %v, want error %s", signer, err, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if !strings.HasPrefix(signer, tc.signer) {
+				t.Fatalf("signer is %q, want %s...", signer, tc.signer)
+			}
+		})
+	}
+}
+
+func TestParseSignedObject(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		objectType plumbing.ObjectType
+		data       string
+		signature  string
+		payload    string
+	}{
+		{
+			name:       "signed commit",
+			objectType: plumbing.CommitObject,
+			data:       "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\nauthor A <a@example.com> 0 +0000\ngpgsig -----BEGIN PGP SIGNATURE-----\n \n abc\n -----END PGP SIGNATURE-----\ncommitter A <a@example.com> 0 +0000\n\nmessage\n gpgsig in the message\n",
+			signature:  "-----BEGIN PGP SIGNATURE-----\n\nabc\n-----END PGP SIGNATURE-----\n",
+			payload:    "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\nauthor A <a@example.com> 0 +0000\ncommitter A <a@example.com> 0 +0000\n\nmessage\n gpgsig in the message\n",
+		},
+		{
+			name:       "unsigned commit",
+			objectType: plumbing.CommitObject,
+			data:       "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n\nmessage\n",
+			payload:    "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n\nmessage\n",
+		},
+		{
+			name:       "SSH signed tag",
+			objectType: plumbing.TagObject,
+			data:       "object 9c0d8c5801c3e7a8165b8983eeea1ca95243aecc\ntype commit\ntag v1.0.0\n\nRelease\n-----BEGIN SSH SIGNATURE-----\nabc\n-----END SSH SIGNATURE-----\n",
+			signature:  "-----BEGIN SSH SIGNATURE-----\nabc\n-----END SSH SIGNATURE-----\n",
+			payload:    "object 9c0d8c5801c3e7a8165b8983eeea1ca95243aecc\ntype commit\ntag v1.0.0\n\nRelease\n",
+		},
+		{
+			name:       "unsigned tag",
+			objectType: plumbing.TagObject,
+			data:       "object 9c0d8c5801c3e7a8165b8983eeea1ca95243aecc\ntype commit\ntag v1.0.0\n\nRelease\n",
+			payload:    "object 9c0d8c5801c3e7a8165b8983eeea1ca95243aecc\ntype commit\ntag v1.0.0\n\nRelease\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			signature, payload := parseSignedObject(tc.objectType, []byte(tc.data))
+			if signature != tc.signature {
+				t.Fatalf("signature is %q, want %q", signature, tc.signature)
+			}
+			if string(payload) != tc.payload {
+				t.Fatalf("payload is %q, want %q", payload, tc.payload)
+			}
+		})
+	}
+}
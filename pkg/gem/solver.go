@@ -15,7 +15,9 @@
 package gem
 
 import (
+	"context"
 	"fmt"
+	"sort"
 
 	"github.com/Masterminds/semver"
 
@@ -30,56 +32,169 @@ func NewSolver(repo Repository) TargetSolver {
 	return &solver{repo}
 }
 
-func (s *solver) bestVersion(versionRange string, versions []RepositoryVersion) (*RepositoryVersion, error) {
+// matchesVersion reports whether the given version satisfies the given constraints under the given prerelease policy.
+func matchesVersion(constraints *semver.Constraints, version *semver.Version, policy gemapi.PrereleasePolicy) bool {
+	if version.Prerelease() == "" || policy == "" {
+		return constraints.Check(version)
+	}
+	if policy == gemapi.PrereleasesExclude {
+		return false
+	}
+
+	release, err := version.SetPrerelease("")
+	if err != nil {
+		return false
+	}
+	return constraints.Check(version) || constraints.Check(&release)
+}
+
+// latestRelease returns the greatest of the given versions that is not a prerelease, if any.
+func latestRelease(versions []RepositoryVersion) *semver.Version {
+	var latest *semver.Version
+	for i := range versions {
+		version := &versions[i].Version
+		if version.Prerelease() == "" && (latest == nil || version.GreaterThan(latest)) {
+			latest = version
+		}
+	}
+	return latest
+}
+
+// excludedVersion returns the exclusion of the given version, if it is excluded.
+func excludedVersion(exclude []gemapi.ExcludedVersion, version *semver.Version) *gemapi.ExcludedVersion {
+	for i := range exclude {
+		excluded, err := semver.NewVersion(exclude[i].Version)
+		if err == nil && excluded.Equal(version) {
+			return &exclude[i]
+		}
+	}
+	return nil
+}
+
+// exclusionReason returns the reason of the given exclusion for messages.
+func exclusionReason(excluded *gemapi.ExcludedVersion) string {
+	if excluded.Reason == "" {
+		return "no reason given"
+	}
+	return excluded.Reason
+}
+
+// matchingVersions returns the given versions that satisfy the given range under the given prerelease policy and
+// are not excluded, greatest first.
+func matchingVersions(versionRange string, versions []RepositoryVersion, policy gemapi.PrereleasePolicy, exclude []gemapi.ExcludedVersion) ([]RepositoryVersion, error) {
 	r, err := semver.NewConstraint(versionRange)
 	if err != nil {
 		return nil, err
 	}
 
-	var best *RepositoryVersion
+	var (
+		candidates []RepositoryVersion
+		excluded   *RepositoryVersion
+		exclusion  *gemapi.ExcludedVersion
+	)
 	for _, version := range versions {
-		if r.Check(&version.Version) && (best == nil || version.Version.GreaterThan(&best.Version)) {
-			v := version
-			best = &v
+		if e := excludedVersion(exclude, &version.Version); e != nil {
+			if matchesVersion(r, &version.Version, policy) && (excluded == nil || version.Version.GreaterThan(&excluded.Version)) {
+				v := version
+				excluded, exclusion = &v, e
+			}
+			continue
 		}
+		candidates = append(candidates, version)
 	}
 
-	if best == nil {
+	// Prereleases are only compared with the releases in the range, so that e.g. `^1.2` matches a preview of
+	// 1.3.0 even if 2.0.0 is already released.
+	var release *semver.Version
+	if policy == gemapi.PrereleasesOnlyIfNewer {
+		var releases []RepositoryVersion
+		for _, version := range candidates {
+			if version.Version.Prerelease() == "" && r.Check(&version.Version) {
+				releases = append(releases, version)
+			}
+		}
+		release = latestRelease(releases)
+	}
+
+	var matching []RepositoryVersion
+	for _, version := range candidates {
+		if release != nil && version.Version.Prerelease() != "" && !version.Version.GreaterThan(release) {
+			continue
+		}
+		if matchesVersion(r, &version.Version, policy) {
+			matching = append(matching, version)
+		}
+	}
+
+	if len(matching) == 0 {
+		if excluded != nil {
+			return nil, fmt.Errorf("no matching version found for range %q, %s is excluded: %s", versionRange, excluded.Name, exclusionReason(exclusion))
+		}
 		return nil, fmt.Errorf("no matching version found for range %q", versionRange)
 	}
-	return best, nil
+
+	sort.SliceStable(matching, func(i, j int) bool { return matching[i].Version.GreaterThan(&matching[j].Version) })
+	return matching, nil
+}
+
+// bestVersion returns the greatest of the given versions that satisfies the given range under the given
+// prerelease policy and is not excluded.
+func bestVersion(versionRange string, versions []RepositoryVersion, policy gemapi.PrereleasePolicy, exclude []gemapi.ExcludedVersion) (*RepositoryVersion, error) {
+	matching, err := matchingVersions(versionRange, versions, policy, exclude)
+	if err != nil {
+		return nil, err
+	}
+	return &matching[0], nil
+}
+
+// versionsWithPrefix returns all versions whose tag has the given prefix.
+func versionsWithPrefix(versions []RepositoryVersion, prefix string) []RepositoryVersion {
+	var out []RepositoryVersion
+	for _, version := range versions {
+		if version.Prefix == prefix {
+			out = append(out, version)
+		}
+	}
+	return out
 }
 
 func (s *solver) Solve(tgt gemapi.Target) (*gemapi.Lock, error) {
+	return s.SolveContext(context.Background(), tgt)
+}
+
+func (s *solver) SolveContext(ctx context.Context, tgt gemapi.Target) (*gemapi.Lock, error) {
 	switch tgt.Type {
 	case gemapi.Revision:
-		hash, err := s.repo.Revision(tgt.Revision)
+		hash, err := s.repo.RevisionContext(ctx, tgt.Revision)
 		if err != nil {
 			return nil, err
 		}
 
 		return &gemapi.Lock{Target: tgt, Resolved: gemapi.Target{Type: gemapi.Revision, Revision: tgt.Revision}, Hash: hash}, nil
 	case gemapi.Version:
-		versions, err := s.repo.Versions()
+		versions, err := s.repo.VersionsContext(ctx)
 		if err != nil {
 			return nil, err
 		}
 
-		best, err := s.bestVersion(tgt.Version, versions)
+		best, err := bestVersion(tgt.Version, versionsWithPrefix(versions, tgt.TagPrefix), tgt.Prereleases, tgt.Exclude)
 		if err != nil {
+			if tgt.TagPrefix != "" {
+				return nil, fmt.Errorf("tag prefix %q: %w", tgt.TagPrefix, err)
+			}
 			return nil, err
 		}
 
-		return &gemapi.Lock{Target: tgt, Resolved: gemapi.Target{Type: gemapi.Version, Version: best.Name}, Hash: best.Hash}, nil
+		return &gemapi.Lock{Target: tgt, Resolved: gemapi.Target{Type: gemapi.Version, Version: best.Name}, Hash: best.Hash, TagHash: best.TagHash}, nil
 	case gemapi.Branch:
-		hash, err := s.repo.Branch(tgt.Branch)
+		hash, err := s.repo.BranchContext(ctx, tgt.Branch)
 		if err != nil {
 			return nil, err
 		}
 
 		return &gemapi.Lock{Target: tgt, Resolved: gemapi.Target{Type: gemapi.Branch, Branch: tgt.Branch}, Hash: hash}, nil
 	case gemapi.Latest:
-		hash, err := s.repo.Latest()
+		hash, err := s.repo.LatestContext(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,105 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gem
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Masterminds/semver"
+	gemapi "github.com/gardener/gem/pkg/gem/api"
+)
+
+func newTestVersions(names ...string) []RepositoryVersion {
+	versions := make([]RepositoryVersion, 0, len(names))
+	for _, name := range names {
+		versions = append(versions, RepositoryVersion{Name: name, Version: *semver.MustParse(name)})
+	}
+	return versions
+}
+
+func TestMatchingVersionsPrereleases(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		versions []string
+		rng      string
+		policy   gemapi.PrereleasePolicy
+		want     []string
+	}{
+		{
+			name:     "only-if-newer compares with the latest release in the range",
+			versions: []string{"1.2.0", "1.3.0-rc.1", "2.0.0"},
+			rng:      "^1.2",
+			policy:   gemapi.PrereleasesOnlyIfNewer,
+			want:     []string{"1.3.0-rc.1", "1.2.0"},
+		},
+		{
+			name:     "only-if-newer does not match prereleases outside of the range",
+			versions: []string{"1.2.0", "1.3.0-rc.1", "2.0.0"},
+			rng:      "~1.2",
+			policy:   gemapi.PrereleasesOnlyIfNewer,
+			want:     []string{"1.2.0"},
+		},
+		{
+			name:     "only-if-newer skips prereleases of released versions in the range",
+			versions: []string{"1.2.0", "1.3.0-rc.1", "1.3.0", "1.3.1-rc.1", "2.0.0"},
+			rng:      "^1.2",
+			policy:   gemapi.PrereleasesOnlyIfNewer,
+			want:     []string{"1.3.1-rc.1", "1.3.0", "1.2.0"},
+		},
+		{
+			name:     "only-if-newer skips prereleases older than the latest release in the range",
+			versions: []string{"1.2.0", "1.2.1-rc.1", "1.3.0", "2.0.0"},
+			rng:      "~1.2 || ~1.3",
+			policy:   gemapi.PrereleasesOnlyIfNewer,
+			want:     []string{"1.3.0", "1.2.0"},
+		},
+		{
+			name:     "only-if-newer without range compares with the latest release",
+			versions: []string{"1.2.0", "1.3.0-rc.1", "2.0.0"},
+			rng:      "*",
+			policy:   gemapi.PrereleasesOnlyIfNewer,
+			want:     []string{"2.0.0", "1.2.0"},
+		},
+		{
+			name:     "include",
+			versions: []string{"1.2.0", "1.3.0-rc.1", "2.0.0"},
+			rng:      "^1.2",
+			policy:   gemapi.PrereleasesInclude,
+			want:     []string{"1.3.0-rc.1", "1.2.0"},
+		},
+		{
+			name:     "exclude",
+			versions: []string{"1.2.0", "1.3.0-rc.1", "2.0.0"},
+			rng:      "^1.2",
+			policy:   gemapi.PrereleasesExclude,
+			want:     []string{"1.2.0"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			matching, err := matchingVersions(tc.rng, newTestVersions(tc.versions...), tc.policy, nil)
+			if err != nil {
+				t.Fatalf("matchingVersions: %v", err)
+			}
+			var got []string
+			for _, version := range matching {
+				got = append(got, version.Name)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("matchingVersions returned %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
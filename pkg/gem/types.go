@@ -15,33 +15,124 @@
 package gem
 
 import (
+	"context"
+
 	"github.com/Masterminds/semver"
 	gemapi "github.com/gardener/gem/pkg/gem/api"
 	"io"
 	"k8s.io/apimachinery/pkg/runtime"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
 )
 
 type RepositoryRegistry interface {
+	// Repository is the same as RepositoryContext called with context.Background().
 	Repository(name string) (Repository, error)
+	RepositoryContext(ctx context.Context, name string) (Repository, error)
+}
+
+// AuthProvider provides the method to authenticate against the given repository URL.
+// A nil AuthMethod means that the repository is accessed anonymously.
+type AuthProvider interface {
+	Auth(ctx context.Context, repositoryURL string) (transport.AuthMethod, error)
+}
+
+// CredentialsAuthProvider is an AuthProvider that chooses the AuthMethod based on Credentials.
+type CredentialsAuthProvider interface {
+	AuthProvider
+	SetCredentials(credentials *gemapi.Credentials)
+}
+
+// GitCache is a RepositoryRegistry that keeps bare clones of the repositories on disk
+// and only fetches incremental changes on subsequent accesses.
+type GitCache interface {
+	RepositoryRegistry
+	Dir() string
+	SetDir(dir string)
+	List() ([]GitCacheEntry, error)
+	Prune(maxAge time.Duration) ([]GitCacheEntry, error)
+	Clear() error
+	// Close releases the entries used by the repositories the cache returned. Until then, Prune and Clear
+	// of other processes leave them untouched.
+	Close() error
 }
 
 type RepositoryVersion struct {
-	Name    string
-	Hash    string
+	// Name is the full name of the tag, including the prefix.
+	Name string
+	// Prefix is the part of the tag name before the version, e.g. `provider-aws/`.
+	Prefix string
+	// Hash is the hash of the commit the version tag points to.
+	Hash string
+	// TagHash is the hash of the tag object if the version tag is annotated.
+	TagHash string
 	Version semver.Version
 }
 
+// ModuleStatus compares the locked version of a module with the available versions.
+// For version targets, the versions are tag names, for all other targets commit hashes.
+type ModuleStatus struct {
+	ModuleKey gemapi.ModuleKey
+	Target    gemapi.Target
+	// Locked is the locked version. It is empty if the module is not locked.
+	Locked string
+	// Wanted is the newest version that satisfies the requirement.
+	Wanted string
+	// Latest is the newest version available, regardless of the requirement.
+	Latest string
+	// Prerelease is the newest prerelease if it is newer than Latest, regardless of the requirement and its
+	// prerelease policy. It is empty if there is none.
+	Prerelease string
+}
+
+// Outdated checks whether a newer version satisfying the requirement than the locked one is available.
+func (s *ModuleStatus) Outdated() bool {
+	return s.Locked != s.Wanted
+}
+
+// ObjectInfo describes a commit or an annotated tag.
+type ObjectInfo struct {
+	// Time is the time the commit was committed or the tag was created.
+	Time time.Time
+	// Subject is the first line of the message.
+	Subject string
+}
+
 type Repository interface {
+	// Revision, Branch, Versions, Latest, File, HasFile, Signature and ObjectInfo are the same as their
+	// context-aware variants called with context.Background().
 	Revision(name string) (string, error)
 	Branch(name string) (string, error)
 	Versions() ([]RepositoryVersion, error)
 	Latest() (string, error)
 	File(hash, path string) (io.Reader, error)
 	HasFile(hash, path string) (bool, error)
+	Signature(hash string) (signature string, payload []byte, err error)
+	ObjectInfo(hash string) (*ObjectInfo, error)
+	RevisionContext(ctx context.Context, name string) (string, error)
+	BranchContext(ctx context.Context, name string) (string, error)
+	VersionsContext(ctx context.Context) ([]RepositoryVersion, error)
+	LatestContext(ctx context.Context) (string, error)
+	FileContext(ctx context.Context, hash, path string) (io.Reader, error)
+	HasFileContext(ctx context.Context, hash, path string) (bool, error)
+	// SignatureContext returns the signature of the commit or annotated tag with the given hash and the payload
+	// it signs. The signature is empty if the object is not signed.
+	SignatureContext(ctx context.Context, hash string) (signature string, payload []byte, err error)
+	// ObjectInfoContext returns the info of the commit or annotated tag with the given hash.
+	ObjectInfoContext(ctx context.Context, hash string) (*ObjectInfo, error)
+}
+
+// SignatureVerifier verifies signatures of commits and tags against trusted keys.
+type SignatureVerifier interface {
+	// Verify verifies the signature of the payload and returns the identity of the signer.
+	Verify(signature string, payload []byte) (signer string, err error)
 }
 
 type TargetSolver interface {
+	// Solve is the same as SolveContext called with context.Background().
 	Solve(target gemapi.Target) (*gemapi.Lock, error)
+	SolveContext(ctx context.Context, target gemapi.Target) (*gemapi.Lock, error)
 }
 
 type TargetSolverFactory interface {
@@ -58,17 +149,61 @@ type UpdatePolicy interface {
 	ShouldUpdateModule(key gemapi.ModuleKey) bool
 }
 
+// UpdateStrategyPolicy is an UpdatePolicy that also overrides the update strategies of the requirements.
+type UpdateStrategyPolicy interface {
+	UpdatePolicy
+	// UpdateStrategy returns the update strategy of the module or an empty strategy to use the one of its requirement.
+	UpdateStrategy(key gemapi.ModuleKey) gemapi.UpdateStrategy
+}
+
 type RepositoryInterface interface {
+	// SolveTarget, Verify, Solve, Ensure, Fetch and Outdated are the same as their context-aware variants
+	// called with context.Background().
 	SolveTarget(target gemapi.Target) (*gemapi.Lock, error)
 	Verify(submodule string, requirement *gemapi.Requirement, lock *gemapi.Lock) error
 	Solve(submodule string, requirement *gemapi.Requirement) (*gemapi.Lock, error)
 	Ensure(submodule string, requirement *gemapi.Requirement, lock *gemapi.Lock, update bool) (*gemapi.Lock, error)
 	Fetch(submodule string, requirement *gemapi.Requirement, lock *gemapi.Lock) ([]runtime.Object, error)
+	Outdated(submodule string, requirement *gemapi.Requirement, lock *gemapi.Lock) (*ModuleStatus, error)
+	SolveTargetContext(ctx context.Context, target gemapi.Target) (*gemapi.Lock, error)
+	VerifyContext(ctx context.Context, submodule string, requirement *gemapi.Requirement, lock *gemapi.Lock) error
+	SolveContext(ctx context.Context, submodule string, requirement *gemapi.Requirement) (*gemapi.Lock, error)
+	EnsureContext(ctx context.Context, submodule string, requirement *gemapi.Requirement, lock *gemapi.Lock, update bool) (*gemapi.Lock, error)
+	FetchContext(ctx context.Context, submodule string, requirement *gemapi.Requirement, lock *gemapi.Lock) ([]runtime.Object, error)
+	OutdatedContext(ctx context.Context, submodule string, requirement *gemapi.Requirement, lock *gemapi.Lock) (*ModuleStatus, error)
 }
 
 type Interface interface {
+	// Jobs returns the maximum number of modules that are processed concurrently.
+	Jobs() int
+	SetJobs(jobs int)
+	// FailFast returns whether processing stops at the first failing module. Otherwise, all modules
+	// are processed, the errors are returned as ModuleErrors and the results of the successful modules
+	// are returned alongside.
+	FailFast() bool
+	SetFailFast(failFast bool)
+	// SignatureVerifier returns the verifier for the signatures of the resolved tags or commits.
+	// If it is nil, signatures are not verified.
+	SignatureVerifier() SignatureVerifier
+	SetSignatureVerifier(verifier SignatureVerifier)
+	// DenyList returns the versions of modules that must never be resolved in addition to the versions
+	// excluded by the requirements. If it is nil, no versions are denied.
+	DenyList() *gemapi.DenyList
+	SetDenyList(denyList *gemapi.DenyList)
+	// Repository, ResolveIncludes, Solve, Fetch, Ensure, Outdated and Verify are the same as their context-aware
+	// variants called with context.Background().
 	Repository(repositoryName string) (RepositoryInterface, error)
+	ResolveIncludes(requirements *gemapi.Requirements, filename string) (*gemapi.Requirements, error)
 	Solve(requirements *gemapi.Requirements) (*gemapi.Locks, error)
 	Fetch(requirements *gemapi.Requirements, locks *gemapi.Locks) ([]runtime.Object, error)
 	Ensure(requirements *gemapi.Requirements, locks *gemapi.Locks, updatePolicy UpdatePolicy) (*gemapi.Locks, error)
+	Outdated(requirements *gemapi.Requirements, locks *gemapi.Locks) ([]ModuleStatus, error)
+	Verify(requirements *gemapi.Requirements, locks *gemapi.Locks, registrations []runtime.Object) error
+	RepositoryContext(ctx context.Context, repositoryName string) (RepositoryInterface, error)
+	ResolveIncludesContext(ctx context.Context, requirements *gemapi.Requirements, filename string) (*gemapi.Requirements, error)
+	SolveContext(ctx context.Context, requirements *gemapi.Requirements) (*gemapi.Locks, error)
+	FetchContext(ctx context.Context, requirements *gemapi.Requirements, locks *gemapi.Locks) ([]runtime.Object, error)
+	EnsureContext(ctx context.Context, requirements *gemapi.Requirements, locks *gemapi.Locks, updatePolicy UpdatePolicy) (*gemapi.Locks, error)
+	OutdatedContext(ctx context.Context, requirements *gemapi.Requirements, locks *gemapi.Locks) ([]ModuleStatus, error)
+	VerifyContext(ctx context.Context, requirements *gemapi.Requirements, locks *gemapi.Locks, registrations []runtime.Object) error
 }
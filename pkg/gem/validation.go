@@ -0,0 +1,363 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gem
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	controllerRegistrationKind = "ControllerRegistration"
+	controllerDeploymentKind   = "ControllerDeployment"
+)
+
+var (
+	supportedKinds = []string{controllerRegistrationKind, controllerDeploymentKind}
+
+	gardencorev1GroupVersion = "core.gardener.cloud/v1"
+
+	supportedRegistrationAPIVersions = []string{gardencorev1beta1.SchemeGroupVersion.String()}
+	supportedDeploymentAPIVersions   = []string{gardencorev1beta1.SchemeGroupVersion.String(), gardencorev1GroupVersion}
+
+	supportedDeploymentPolicies = sets.NewString(
+		string(gardencorev1beta1.ControllerDeploymentPolicyOnDemand),
+		string(gardencorev1beta1.ControllerDeploymentPolicyAlways),
+		string(gardencorev1beta1.ControllerDeploymentPolicyAlwaysExceptNoShoots),
+	)
+)
+
+// controllerDeployment mirrors the ControllerDeployment resource of the gardener core API, which is not
+// part of the vendored API types. It combines the fields of all supported API versions: `core.gardener.cloud/v1beta1`
+// deploys via type and provider config, `core.gardener.cloud/v1` via helm.
+type controllerDeployment struct {
+	metav1.TypeMeta          `json:",inline"`
+	metav1.ObjectMeta        `json:"metadata,omitempty"`
+	Type                     string                 `json:"type,omitempty"`
+	ProviderConfig           *runtime.RawExtension  `json:"providerConfig,omitempty"`
+	Helm                     map[string]interface{} `json:"helm,omitempty"`
+	InjectGardenerKubeconfig *bool                  `json:"injectGardenerKubeconfig,omitempty"`
+}
+
+// controllerRegistrationDeploymentRefs returns the references of a ControllerRegistration to ControllerDeployments
+// and removes them from the given object, since they are not part of the vendored API types.
+func controllerRegistrationDeploymentRefs(obj *unstructured.Unstructured) ([]interface{}, error) {
+	refs, ok, err := unstructured.NestedSlice(obj.Object, "spec", "deployment", "deploymentRefs")
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	unstructured.RemoveNestedField(obj.Object, "spec", "deployment", "deploymentRefs")
+	return refs, nil
+}
+
+func validateDeploymentRefs(refs []interface{}, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	names := sets.NewString()
+	for i, ref := range refs {
+		idxPath := fldPath.Index(i)
+
+		r, ok := ref.(map[string]interface{})
+		if !ok {
+			allErrs = append(allErrs, field.Invalid(idxPath, ref, "must be an object"))
+			continue
+		}
+
+		name, _ := r["name"].(string)
+		if len(name) == 0 {
+			allErrs = append(allErrs, field.Required(idxPath.Child("name"), "field is required"))
+			continue
+		}
+		if names.Has(name) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("name"), name))
+		}
+		names.Insert(name)
+	}
+
+	return allErrs
+}
+
+// decode decodes the given object into out, ignoring fields that are unknown to out, e.g. because they were
+// added in a later version of the gardener API. It returns the paths of the ignored fields.
+func decode(obj runtime.Object, out interface{}) ([]string, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return nil, err
+	}
+
+	known, err := json.Marshal(out)
+	if err != nil {
+		return nil, err
+	}
+	var original, decoded interface{}
+	if err := json.Unmarshal(data, &original); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(known, &decoded); err != nil {
+		return nil, err
+	}
+	return unknownFields(original, decoded, nil), nil
+}
+
+// unknownFields returns the paths of the non-empty fields of original that are missing in decoded.
+func unknownFields(original, decoded interface{}, fldPath *field.Path) []string {
+	var unknown []string
+	switch o := original.(type) {
+	case map[string]interface{}:
+		d, _ := decoded.(map[string]interface{})
+		keys := make([]string, 0, len(o))
+		for key := range o {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			value, ok := d[key]
+			if !ok {
+				if !isEmptyValue(o[key]) {
+					unknown = append(unknown, fldPath.Child(key).String())
+				}
+				continue
+			}
+			unknown = append(unknown, unknownFields(o[key], value, fldPath.Child(key))...)
+		}
+	case []interface{}:
+		d, _ := decoded.([]interface{})
+		for i := range o {
+			if i < len(d) {
+				unknown = append(unknown, unknownFields(o[i], d[i], fldPath.Index(i))...)
+			}
+		}
+	}
+	return unknown
+}
+
+// isEmptyValue reports whether the given decoded JSON value is empty and thus may be omitted when encoding.
+func isEmptyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case float64:
+		return v == 0
+	case string:
+		return v == ""
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+func validateAPIVersion(apiVersion string, supported []string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for _, version := range supported {
+		if apiVersion == version {
+			return allErrs
+		}
+	}
+	allErrs = append(allErrs, field.NotSupported(fldPath, apiVersion, supported))
+	return allErrs
+}
+
+// ValidateControllerRegistration validates a ControllerRegistration like the gardener API server does.
+func ValidateControllerRegistration(registration *gardencorev1beta1.ControllerRegistration) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	allErrs = append(allErrs, validateAPIVersion(registration.APIVersion, supportedRegistrationAPIVersions, field.NewPath("apiVersion"))...)
+	allErrs = append(allErrs, apivalidation.ValidateObjectMeta(&registration.ObjectMeta, false, apivalidation.NameIsDNSLabel, field.NewPath("metadata"))...)
+	allErrs = append(allErrs, validateControllerRegistrationSpec(&registration.Spec, field.NewPath("spec"))...)
+
+	return allErrs
+}
+
+func validateControllerRegistrationSpec(spec *gardencorev1beta1.ControllerRegistrationSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	var (
+		resourcesPath = fldPath.Child("resources")
+		resources     = make(map[string]sets.String, len(spec.Resources))
+		primary       = false
+	)
+	for i, resource := range spec.Resources {
+		idxPath := resourcesPath.Index(i)
+
+		if len(resource.Kind) == 0 {
+			allErrs = append(allErrs, field.Required(idxPath.Child("kind"), "field is required"))
+		} else if !extensionsv1alpha1.ExtensionKinds.Has(resource.Kind) {
+			allErrs = append(allErrs, field.NotSupported(idxPath.Child("kind"), resource.Kind, extensionsv1alpha1.ExtensionKinds.List()))
+		}
+
+		if len(resource.Type) == 0 {
+			allErrs = append(allErrs, field.Required(idxPath.Child("type"), "field is required"))
+		}
+
+		if resources[resource.Kind].Has(resource.Type) {
+			allErrs = append(allErrs, field.Duplicate(idxPath, fmt.Sprintf("%s/%s", resource.Kind, resource.Type)))
+		}
+		if resources[resource.Kind] == nil {
+			resources[resource.Kind] = sets.NewString()
+		}
+		resources[resource.Kind].Insert(resource.Type)
+
+		if resource.Kind != extensionsv1alpha1.ExtensionResource {
+			if resource.GloballyEnabled != nil {
+				allErrs = append(allErrs, field.Forbidden(idxPath.Child("globallyEnabled"), fmt.Sprintf("field must not be set when kind != %s", extensionsv1alpha1.ExtensionResource)))
+			}
+			if resource.ReconcileTimeout != nil {
+				allErrs = append(allErrs, field.Forbidden(idxPath.Child("reconcileTimeout"), fmt.Sprintf("field must not be set when kind != %s", extensionsv1alpha1.ExtensionResource)))
+			}
+		}
+
+		if resource.Primary == nil || *resource.Primary {
+			primary = true
+		}
+	}
+
+	if deployment := spec.Deployment; deployment != nil {
+		deploymentPath := fldPath.Child("deployment")
+
+		if policy := deployment.Policy; policy != nil && !supportedDeploymentPolicies.Has(string(*policy)) {
+			allErrs = append(allErrs, field.NotSupported(deploymentPath.Child("policy"), *policy, supportedDeploymentPolicies.List()))
+		}
+
+		if deployment.SeedSelector != nil {
+			if primary {
+				allErrs = append(allErrs, field.Forbidden(deploymentPath.Child("seedSelector"), "specifying a seed selector is not allowed when controlling resources primarily"))
+			}
+			allErrs = append(allErrs, metav1validation.ValidateLabelSelector(deployment.SeedSelector, deploymentPath.Child("seedSelector"))...)
+		}
+	}
+
+	return allErrs
+}
+
+func validateControllerDeployment(deployment *controllerDeployment) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	allErrs = append(allErrs, validateAPIVersion(deployment.APIVersion, supportedDeploymentAPIVersions, field.NewPath("apiVersion"))...)
+	allErrs = append(allErrs, apivalidation.ValidateObjectMeta(&deployment.ObjectMeta, false, apivalidation.NameIsDNSSubdomain, field.NewPath("metadata"))...)
+
+	if deployment.APIVersion == gardencorev1beta1.SchemeGroupVersion.String() && len(deployment.Type) == 0 {
+		allErrs = append(allErrs, field.Required(field.NewPath("type"), "field is required"))
+	}
+
+	return allErrs
+}
+
+// versionFields returns the fields of the deployment that are not part of its API version.
+func (d *controllerDeployment) versionFields() []string {
+	var fields []string
+	switch d.APIVersion {
+	case gardencorev1beta1.SchemeGroupVersion.String():
+		if d.Helm != nil {
+			fields = append(fields, "helm")
+		}
+		if d.InjectGardenerKubeconfig != nil {
+			fields = append(fields, "injectGardenerKubeconfig")
+		}
+	case gardencorev1GroupVersion:
+		if d.Type != "" {
+			fields = append(fields, "type")
+		}
+		if d.ProviderConfig != nil {
+			fields = append(fields, "providerConfig")
+		}
+	}
+	return fields
+}
+
+// ValidateControllerRegistrationObject decodes the given object into the gardener core API type of its kind and
+// validates it. Only ControllerRegistrations and ControllerDeployments are supported. Fields that are unknown to
+// the API types, e.g. because they were added in a later version of the gardener API, are not validated but
+// returned as warnings.
+func ValidateControllerRegistrationObject(obj runtime.Object) ([]string, error) {
+	var (
+		kind    = obj.GetObjectKind().GroupVersionKind().Kind
+		name    string
+		unknown []string
+		allErrs field.ErrorList
+	)
+	switch kind {
+	case controllerRegistrationKind:
+		u := &unstructured.Unstructured{}
+		if _, err := decode(obj, u); err != nil {
+			return nil, &ValidationError{Kind: kind, Err: err}
+		}
+		refs, err := controllerRegistrationDeploymentRefs(u)
+		if err != nil {
+			return nil, &ValidationError{Kind: kind, Name: u.GetName(), Err: err}
+		}
+
+		registration := &gardencorev1beta1.ControllerRegistration{}
+		if unknown, err = decode(u, registration); err != nil {
+			return nil, &ValidationError{Kind: kind, Name: u.GetName(), Err: err}
+		}
+		name, allErrs = registration.Name, ValidateControllerRegistration(registration)
+		allErrs = append(allErrs, validateDeploymentRefs(refs, field.NewPath("spec", "deployment", "deploymentRefs"))...)
+	case controllerDeploymentKind:
+		deployment := &controllerDeployment{}
+		var err error
+		if unknown, err = decode(obj, deployment); err != nil {
+			return nil, &ValidationError{Kind: kind, Err: err}
+		}
+		unknown = append(unknown, deployment.versionFields()...)
+		name, allErrs = deployment.Name, validateControllerDeployment(deployment)
+	default:
+		allErrs = field.ErrorList{field.NotSupported(field.NewPath("kind"), kind, supportedKinds)}
+	}
+
+	if len(allErrs) > 0 {
+		return nil, &ValidationError{Kind: kind, Name: name, Err: allErrs.ToAggregate()}
+	}
+
+	warnings := make([]string, 0, len(unknown))
+	for _, path := range unknown {
+		warnings = append(warnings, fmt.Sprintf("%s %q: unknown field %s is not validated", kind, name, path))
+	}
+	return warnings, nil
+}
+
+// ValidateControllerRegistrationObjects validates all given objects and returns the warnings of all of them,
+// see ValidateControllerRegistrationObject.
+func ValidateControllerRegistrationObjects(objs []runtime.Object) ([]string, error) {
+	var warnings []string
+	for _, obj := range objs {
+		w, err := ValidateControllerRegistrationObject(obj)
+		if err != nil {
+			return nil, err
+		}
+		warnings = append(warnings, w...)
+	}
+	return warnings, nil
+}
@@ -0,0 +1,248 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gem
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	gemapi "github.com/gardener/gem/pkg/gem/api"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// VerifyReason is the reason why a module does not match its lock.
+type VerifyReason string
+
+const (
+	// VerifyReasonMissingLock means that no lock is recorded for the module.
+	VerifyReasonMissingLock VerifyReason = "MissingLock"
+	// VerifyReasonUnsatisfiedConstraint means that the lock does not satisfy the requirement.
+	VerifyReasonUnsatisfiedConstraint VerifyReason = "UnsatisfiedConstraint"
+	// VerifyReasonExcludedVersion means that the locked version is excluded by the requirement or the deny list.
+	VerifyReasonExcludedVersion VerifyReason = "ExcludedVersion"
+	// VerifyReasonMissingFile means that the registration file does not exist at the locked hash.
+	VerifyReasonMissingFile VerifyReason = "MissingFile"
+	// VerifyReasonUntrustedSignature means that the resolved tag or commit is not signed by a trusted signer.
+	VerifyReasonUntrustedSignature VerifyReason = "UntrustedSignature"
+	// VerifyReasonConstraintConflict means that the locked versions violate the constraints declared by the
+	// modules or their requirements.
+	VerifyReasonConstraintConflict VerifyReason = "ConstraintConflict"
+	// VerifyReasonContentDrift means that the written registrations differ from the fetched ones
+	// or that the fetched file does not match the digest recorded in the lock.
+	VerifyReasonContentDrift VerifyReason = "ContentDrift"
+)
+
+// VerifyError is the error of a module that does not match its lock.
+type VerifyError struct {
+	Reason  VerifyReason
+	Message string
+}
+
+func (e *VerifyError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Reason, e.Message)
+}
+
+// registrationKey identifies a registration by its kind, namespace and name.
+func registrationKey(obj runtime.Object) (string, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return "", err
+	}
+
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	if namespace := accessor.GetNamespace(); namespace != "" {
+		return kind + "/" + namespace + "/" + accessor.GetName(), nil
+	}
+	return kind + "/" + accessor.GetName(), nil
+}
+
+// registrationIndex maps the keys of registrations to their serialized form.
+type registrationIndex struct {
+	keys    []string
+	encoded map[string][]byte
+}
+
+func newRegistrationIndex(registrations []runtime.Object) (*registrationIndex, error) {
+	index := &registrationIndex{encoded: make(map[string][]byte)}
+	for _, registration := range registrations {
+		key, err := registrationKey(registration)
+		if err != nil {
+			return nil, err
+		}
+
+		encoded, err := runtime.Encode(unstructured.UnstructuredJSONScheme, registration)
+		if err != nil {
+			return nil, err
+		}
+
+		index.keys = append(index.keys, key)
+		index.encoded[key] = encoded
+	}
+	return index, nil
+}
+
+// drift compares the given fetched registrations with the index and describes all differences.
+func (r *registrationIndex) drift(fetched *registrationIndex) []string {
+	var diffs []string
+	for _, key := range fetched.keys {
+		encoded, ok := r.encoded[key]
+		switch {
+		case !ok:
+			diffs = append(diffs, fmt.Sprintf("%s is missing", key))
+		case !bytes.Equal(encoded, fetched.encoded[key]):
+			diffs = append(diffs, fmt.Sprintf("%s differs", key))
+		}
+	}
+	return diffs
+}
+
+// verifyError classifies errors of verifying or fetching a lock.
+func verifyError(msg string, err error) error {
+	var (
+		missingFileErr    *MissingFileError
+		digestMismatchErr *DigestMismatchError
+		signatureErr      *SignatureError
+	)
+	switch {
+	case errors.As(err, &missingFileErr):
+		return &VerifyError{VerifyReasonMissingFile, missingFileErr.Error()}
+	case errors.As(err, &digestMismatchErr):
+		return &VerifyError{VerifyReasonContentDrift, digestMismatchErr.Error()}
+	case errors.As(err, &signatureErr):
+		return &VerifyError{VerifyReasonUntrustedSignature, signatureErr.Error()}
+	default:
+		return fmt.Errorf("%s: %w", msg, err)
+	}
+}
+
+func (g *gem) Verify(requirements *gemapi.Requirements, locks *gemapi.Locks, registrations []runtime.Object) error {
+	return g.VerifyContext(context.Background(), requirements, locks, registrations)
+}
+
+// VerifyContext checks that the locks satisfy the requirements and that they can be fetched, without
+// solving anything anew. If registrations is not nil, it has to match what FetchContext would return.
+// Mismatches of modules are reported as ModuleErrors wrapping a VerifyError. Differences of the
+// registrations that cannot be attributed to a module are only reported if all modules match, so are
+// conflicts between the constraints of the locked versions.
+func (g *gem) VerifyContext(ctx context.Context, requirements *gemapi.Requirements, locks *gemapi.Locks, registrations []runtime.Object) error {
+	if locks == nil {
+		locks = &gemapi.Locks{}
+	}
+
+	var written *registrationIndex
+	if registrations != nil {
+		var err error
+		if written, err = newRegistrationIndex(registrations); err != nil {
+			return fmt.Errorf("could not index registrations: %w", err)
+		}
+	}
+
+	fetched := make([]*registrationIndex, len(requirements.Requirements))
+	if err := g.forEachModule(ctx, requirements, func(i int, moduleKey gemapi.ModuleKey, requirement *gemapi.Requirement) error {
+		log := withModuleKeyRequirementLogger(g.log, moduleKey, requirement)
+		log.Info("Verifying")
+
+		lock, ok := locks.Locks[moduleKey]
+		if !ok {
+			return &VerifyError{VerifyReasonMissingLock, "no lock recorded"}
+		}
+
+		log = withLockLogger(log, lock)
+		if excluded := excludedLock(requirement, lock); excluded != nil {
+			return &VerifyError{VerifyReasonExcludedVersion, fmt.Sprintf("locked version %s is excluded: %s", lock.Resolved.Version, exclusionReason(excluded))}
+		}
+		if !isRequirementSatisfiedByLock(effectiveRequirement(moduleKey.Submodule, requirement), lock) {
+			return &VerifyError{VerifyReasonUnsatisfiedConstraint, fmt.Sprintf("lock %v does not satisfy the requirement", lock)}
+		}
+
+		log.Debug("Retrieving repository")
+		repositoryInterface, err := g.RepositoryContext(ctx, repositoryURL(moduleKey, requirement))
+		if err != nil {
+			return fmt.Errorf("could not retrieve repository: %w", err)
+		}
+
+		log.Debug("Verifying lock")
+		if err := repositoryInterface.VerifyContext(ctx, moduleKey.Submodule, requirement, lock); err != nil {
+			return verifyError("could not verify lock", err)
+		}
+
+		if written == nil {
+			log.Info("Successfully verified")
+			return nil
+		}
+
+		log.Debug("Comparing registrations")
+		registration, err := repositoryInterface.FetchContext(ctx, moduleKey.Submodule, requirement, lock)
+		if err != nil {
+			return verifyError("could not fetch registration", err)
+		}
+
+		index, err := newRegistrationIndex(registration)
+		if err != nil {
+			return fmt.Errorf("could not index registration: %w", err)
+		}
+		if diffs := written.drift(index); len(diffs) > 0 {
+			return &VerifyError{VerifyReasonContentDrift, strings.Join(diffs, ", ")}
+		}
+
+		log.Info("Successfully verified")
+		fetched[i] = index
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if _, err := g.solveConstraints(ctx, requirements, func(moduleKey gemapi.ModuleKey, requirement *gemapi.Requirement) (*constrainedModule, error) {
+		return g.lockedModule(ctx, moduleKey, requirement, locks.Locks[moduleKey])
+	}); err != nil {
+		var conflictErr *ConstraintConflictError
+		if errors.As(err, &conflictErr) {
+			return &VerifyError{VerifyReasonConstraintConflict, strings.Join(conflictErr.Conflicts, "; ")}
+		}
+		return fmt.Errorf("could not check constraints: %w", err)
+	}
+
+	if written == nil {
+		return nil
+	}
+
+	var keys []string
+	for _, index := range fetched {
+		keys = append(keys, index.keys...)
+	}
+	expected := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		expected[key] = struct{}{}
+	}
+
+	var unexpected []string
+	for _, key := range written.keys {
+		if _, ok := expected[key]; !ok {
+			unexpected = append(unexpected, key)
+		}
+	}
+	if len(unexpected) > 0 {
+		return &VerifyError{VerifyReasonContentDrift, fmt.Sprintf("unexpected registrations %s", strings.Join(unexpected, ", "))}
+	}
+	if strings.Join(keys, "\n") != strings.Join(written.keys, "\n") {
+		return &VerifyError{VerifyReasonContentDrift, "registrations are not in the expected order"}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flock
+
+import (
+	"context"
+	"os"
+	"time"
+
+	osutil "github.com/gardener/gem/pkg/util/os"
+)
+
+const (
+	minRetryDelay = 10 * time.Millisecond
+	maxRetryDelay = time.Second
+)
+
+// Lock is an advisory lock backed by a file that can be shared between processes. It is either held
+// exclusively or shared with other holders of a shared lock.
+type Lock struct {
+	path string
+	file *os.File
+}
+
+// New creates a new Lock for the given path. The file is created on first use.
+func New(path string) *Lock {
+	return &Lock{path: path}
+}
+
+func (l *Lock) open() error {
+	if l.file != nil {
+		return nil
+	}
+
+	if err := osutil.EnsureDirnameDirectories(l.path); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+
+	l.file = f
+	return nil
+}
+
+// Lock blocks until the lock is acquired exclusively.
+func (l *Lock) Lock() error {
+	return l.lock(false)
+}
+
+// RLock blocks until the lock is acquired shared.
+func (l *Lock) RLock() error {
+	return l.lock(true)
+}
+
+func (l *Lock) lock(shared bool) error {
+	if err := l.open(); err != nil {
+		return err
+	}
+
+	if err := lock(l.file, shared); err != nil {
+		_ = l.close()
+		return err
+	}
+	return nil
+}
+
+// LockContext blocks until the lock is acquired exclusively or the context is done.
+func (l *Lock) LockContext(ctx context.Context) error {
+	return l.lockContext(ctx, l.TryLock)
+}
+
+// RLockContext blocks until the lock is acquired shared or the context is done.
+func (l *Lock) RLockContext(ctx context.Context) error {
+	return l.lockContext(ctx, l.TryRLock)
+}
+
+func (l *Lock) lockContext(ctx context.Context, tryLock func() (bool, error)) error {
+	delay := minRetryDelay
+	for {
+		ok, err := tryLock()
+		if err != nil || ok {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
+}
+
+// TryLock tries to acquire the lock exclusively without blocking and reports whether it succeeded.
+func (l *Lock) TryLock() (bool, error) {
+	return l.tryLock(false)
+}
+
+// TryRLock tries to acquire the lock shared without blocking and reports whether it succeeded.
+func (l *Lock) TryRLock() (bool, error) {
+	return l.tryLock(true)
+}
+
+func (l *Lock) tryLock(shared bool) (bool, error) {
+	if err := l.open(); err != nil {
+		return false, err
+	}
+
+	ok, err := tryLock(l.file, shared)
+	if err != nil || !ok {
+		_ = l.close()
+		return false, err
+	}
+	return true, nil
+}
+
+// Unlock releases the lock.
+func (l *Lock) Unlock() error {
+	if l.file == nil {
+		return nil
+	}
+
+	if err := unlock(l.file); err != nil {
+		_ = l.close()
+		return err
+	}
+	return l.close()
+}
+
+func (l *Lock) close() error {
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
@@ -20,3 +20,10 @@ func StringDerefOr(s *string, or string) string {
 	}
 	return or
 }
+
+func BoolDerefOr(b *bool, or bool) bool {
+	if b != nil {
+		return *b
+	}
+	return or
+}
@@ -0,0 +1,139 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sshsig verifies signatures in the format created by `ssh-keygen -Y sign`,
+// as used by git for SSH-signed commits and tags.
+package sshsig
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// PEMType is the type of the PEM block of an armored signature.
+	PEMType = "SSH SIGNATURE"
+
+	magic   = "SSHSIG"
+	version = 1
+)
+
+// Signature is a parsed SSH signature.
+type Signature struct {
+	PublicKey     ssh.PublicKey
+	Namespace     string
+	HashAlgorithm string
+	Signature     *ssh.Signature
+}
+
+type wireSignature struct {
+	Version       uint32
+	PublicKey     []byte
+	Namespace     string
+	Reserved      []byte
+	HashAlgorithm string
+	Signature     []byte
+}
+
+type signedData struct {
+	Namespace     string
+	Reserved      []byte
+	HashAlgorithm string
+	Hash          []byte
+}
+
+// ParseArmored parses an armored signature.
+func ParseArmored(armored []byte) (*Signature, error) {
+	block, _ := pem.Decode(armored)
+	if block == nil || block.Type != PEMType {
+		return nil, errors.New("no armored SSH signature found")
+	}
+	return Parse(block.Bytes)
+}
+
+// Parse parses the binary form of a signature.
+func Parse(data []byte) (*Signature, error) {
+	if !bytes.HasPrefix(data, []byte(magic)) {
+		return nil, errors.New("invalid SSH signature magic")
+	}
+
+	var w wireSignature
+	if err := ssh.Unmarshal(data[len(magic):], &w); err != nil {
+		return nil, fmt.Errorf("invalid SSH signature: %w", err)
+	}
+	if w.Version != version {
+		return nil, fmt.Errorf("unsupported SSH signature version %d", w.Version)
+	}
+
+	publicKey, err := ssh.ParsePublicKey(w.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key of SSH signature: %w", err)
+	}
+
+	signature := &ssh.Signature{}
+	if err := ssh.Unmarshal(w.Signature, signature); err != nil {
+		return nil, fmt.Errorf("invalid SSH signature blob: %w", err)
+	}
+
+	return &Signature{
+		PublicKey:     publicKey,
+		Namespace:     w.Namespace,
+		HashAlgorithm: w.HashAlgorithm,
+		Signature:     signature,
+	}, nil
+}
+
+func newHash(algorithm string) (hash.Hash, error) {
+	switch algorithm {
+	case "sha256":
+		return sha256.New(), nil
+	case "sha512":
+		return sha512.New(), nil
+	default:
+		return nil, fmt.Errorf("unsupported hash algorithm %q", algorithm)
+	}
+}
+
+// Verify checks that the signature was made for the given namespace over the given message
+// by the public key contained in the signature. The caller has to check whether that key is trusted.
+func (s *Signature) Verify(namespace string, message []byte) error {
+	if s.Namespace != namespace {
+		return fmt.Errorf("signature was made for namespace %q instead of %q", s.Namespace, namespace)
+	}
+
+	h, err := newHash(s.HashAlgorithm)
+	if err != nil {
+		return err
+	}
+	h.Write(message)
+
+	data := append([]byte(magic), ssh.Marshal(signedData{
+		Namespace:     s.Namespace,
+		HashAlgorithm: s.HashAlgorithm,
+		Hash:          h.Sum(nil),
+	})...)
+	return s.PublicKey.Verify(data, s.Signature)
+}
+
+// Fingerprint returns the SHA256 fingerprint of the public key of the signature.
+func (s *Signature) Fingerprint() string {
+	return ssh.FingerprintSHA256(s.PublicKey)
+}
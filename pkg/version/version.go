@@ -0,0 +1,35 @@
+// Copyright 2020 SAP SE or an SAP affiliate company. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package version provides the version of gem.
+package version
+
+import "runtime/debug"
+
+// Version is the version of gem. It can be set at build time via
+// `-ldflags "-X github.com/gardener/gem/pkg/version.Version=<version>"`.
+var Version = ""
+
+// Get returns the version of gem. If it was not set at build time, the version of the main module is used,
+// which is set if gem was installed via `go get`.
+func Get() string {
+	if Version != "" {
+		return Version
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "(devel)"
+}